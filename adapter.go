@@ -1,70 +1,157 @@
 package gomolreplay
 
 import (
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/aphistic/gomol"
 )
 
-const (
-	// AttrReplay is an attribute assigned to a message that has
-	// been replayed at a different log level. Its value is equal
-	// to the original log level.
-	AttrReplay = "replayed-from-level"
-)
-
 type (
 	// Adapter provides a way to replay a sequence of message, in
-	// the order they were logged, at a higher log level.
+	// the order they were logged, at a higher log level. An adapter
+	// is safe for concurrent use by multiple goroutines.
 	Adapter struct {
-		base            gomol.WrappableLogger
-		clock           clock
-		journal         []*logMessage
-		journaledLevels []gomol.LogLevel
-		replayingAt     *gomol.LogLevel
+		// journaledLevels is a bitmask of the journaled levels. It is
+		// accessed atomically and is the first field in the struct so
+		// that it is 64-bit aligned on 32-bit platforms.
+		journaledLevels uint64
+
+		base              gomol.WrappableLogger
+		clock             clock
+		journal           *journal
+		rules             []Rule
+		sequence          uint64
+		replayTarget      gomol.WrappableLogger
+		replaying         *replayState
+		attrKeys          attrKeys
+		replayID          func() string
+		oneShot           bool
+		escalationMarkers bool
+		eagerFormatting   bool
+		bufferOnly        bool
+		originalLevel     bool
+		trigger           func(gomol.LogLevel) bool
+		triggerOrder      TriggerOrder
+		finishLevel       gomol.LogLevel
+		swallowPanics     bool
+		dieReplayLevel    *gomol.LogLevel
+		preExitHooks      []func()
+		mutex             sync.Mutex
 	}
 
+	// TriggerOrder determines whether an automatically triggered replay
+	// happens before or after the triggering message is logged.
+	TriggerOrder int
+
 	logMessage struct {
 		level gomol.LogLevel
 		attrs *gomol.Attrs
 		ts    time.Time
 		msg   string
 		args  []interface{}
+		seq   uint64
+		size  int64
+
+		// original is the format string with which the message was
+		// logged if msg was replaced when the message was formatted.
+		original string
 	}
 )
 
+const (
+	// ReplayBeforeTrigger replays the journal before the triggering message
+	// is logged, so that messages are sent in the order they were logged.
+	// This is the default order.
+	ReplayBeforeTrigger TriggerOrder = iota
+
+	// ReplayAfterTrigger replays the journal after the triggering message
+	// is logged, so that the triggering message is sent first.
+	ReplayAfterTrigger
+)
+
 // NewAdapter creates an Adapter which wraps the given logger.
 func NewAdapter(logger gomol.WrappableLogger, journaledLevels ...gomol.LogLevel) *Adapter {
-	return newAdapterWithClock(logger, &realClock{}, journaledLevels...)
+	return NewConfiguredAdapter(logger, WithJournaledLevels(journaledLevels...))
 }
 
-func newAdapterWithClock(logger gomol.WrappableLogger, clock clock, journaledLevels ...gomol.LogLevel) *Adapter {
-	return &Adapter{
-		base:            logger,
-		clock:           clock,
-		journal:         []*logMessage{},
-		journaledLevels: journaledLevels,
+// NewConfiguredAdapter creates an Adapter which wraps the given logger
+// and is configured by the given config functions.
+func NewConfiguredAdapter(logger gomol.WrappableLogger, configs ...ConfigFunc) *Adapter {
+	a := &Adapter{
+		base:         logger,
+		clock:        &realClock{},
+		journal:      &journal{},
+		replayTarget: logger,
+		attrKeys:     attrKeys{level: AttrReplay},
+		replayID:     randomID,
+		finishLevel:  gomol.LevelError,
+	}
+
+	for _, f := range configs {
+		f(a)
 	}
+
+	return a
+}
+
+func newAdapterWithClock(logger gomol.WrappableLogger, clock clock, journaledLevels ...gomol.LogLevel) *Adapter {
+	return NewConfiguredAdapter(logger, withClock(clock), WithJournaledLevels(journaledLevels...))
 }
 
 // LogWithTime will log a message at the provided level to all loggers added
 // to the logger wrapped by this RollupAdapter. It is similar to Log except
 // the timestamp will be set to the value of ts.
 func (a *Adapter) LogWithTime(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, args ...interface{}) error {
-	if err := a.base.LogWithTime(level, ts, attrs, msg, args...); err != nil {
+	if err := a.triggerReplay(level, ReplayBeforeTrigger); err != nil {
 		return err
 	}
 
-	if a.shouldJournal(level) {
-		message := &logMessage{level: level, attrs: attrs, ts: ts, msg: msg, args: args}
+	if err := a.logWithTime(level, ts, attrs, msg, args...); err != nil {
+		return err
+	}
 
-		if a.replayingAt != nil {
-			if err := a.replayMessage(message); err != nil {
-				return err
-			}
+	return a.triggerReplay(level, ReplayAfterTrigger)
+}
+
+func (a *Adapter) logWithTime(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, args ...interface{}) error {
+	journaled, forwarded := a.route(level, msg, attrs)
+	return a.record(journaled, forwarded, level, ts, attrs, msg, args...)
+}
+
+// record forwards the given message to the wrapped logger and journals it
+// as determined by route.
+func (a *Adapter) record(journaled, forwarded bool, level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, args ...interface{}) error {
+	if forwarded {
+		if err := a.base.LogWithTime(level, ts, attrs, msg, args...); err != nil {
+			return err
 		}
+	}
+
+	if !journaled {
+		return nil
+	}
 
-		a.journal = append(a.journal, message)
+	message := &logMessage{level: level, attrs: attrs, ts: ts, msg: msg, args: args}
+	if a.eagerFormatting {
+		message.snapshot()
+	}
+
+	// Journal the message and read the replay state atomically so that
+	// a concurrent call to Replay either sees this message in the journal
+	// or this call sees the new replay level - never both and never neither.
+	a.mutex.Lock()
+	a.sequence++
+	message.seq = a.sequence
+	a.journal.expireOldest(a.clock.Now())
+	a.journal.add(message)
+	replaying := a.replaying
+	journalSize := a.journal.len()
+	a.mutex.Unlock()
+
+	if replaying != nil {
+		return a.replayMessage(replaying, message, a.batchAttrs(replaying, journalSize))
 	}
 
 	return nil
@@ -73,11 +160,29 @@ func (a *Adapter) LogWithTime(level gomol.LogLevel, ts time.Time, attrs *gomol.A
 // Log will log a message at the provided level to all loggers added to the
 // logger wrapped by this RollupAdapter.
 func (a *Adapter) Log(level gomol.LogLevel, attrs *gomol.Attrs, msg string, args ...interface{}) error {
-	if !a.shouldJournal(level) {
+	if err := a.triggerReplay(level, ReplayBeforeTrigger); err != nil {
+		return err
+	}
+
+	if err := a.log(level, attrs, msg, args...); err != nil {
+		return err
+	}
+
+	return a.triggerReplay(level, ReplayAfterTrigger)
+}
+
+func (a *Adapter) log(level gomol.LogLevel, attrs *gomol.Attrs, msg string, args ...interface{}) error {
+	journaled, forwarded := a.route(level, msg, attrs)
+
+	if !journaled {
+		if !forwarded {
+			return nil
+		}
+
 		return a.base.Log(level, attrs, msg, args...)
 	}
 
-	return a.LogWithTime(level, a.clock.Now(), attrs, msg, args...)
+	return a.record(journaled, forwarded, level, a.clock.Now(), attrs, msg, args...)
 }
 
 // ShutdownLoggers will call the wrapped logger's ShutdownLoggers method.
@@ -85,50 +190,62 @@ func (a *Adapter) ShutdownLoggers() error {
 	return a.base.ShutdownLoggers()
 }
 
-// Replay will cause all of the messages previously logged at one of the
-// journaled levels to be re-set at the given level. All future messages
-// logged at one of the journaled levels will be replayed immediately.
-func (a *Adapter) Replay(level gomol.LogLevel) error {
-	if a.replayingAt != nil && *a.replayingAt <= level {
-		return nil
-	}
+// Discard removes all messages from the journal without replaying them.
+// The bytes held by the journal are returned to any shared budget. This
+// does not change whether or not the adapter is currently replaying.
+func (a *Adapter) Discard() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
 
-	a.replayingAt = &level
+	a.journal.reset()
+}
 
-	for _, message := range a.journal {
-		if err := a.replayMessage(message); err != nil {
-			return err
-		}
+// Finish ends the adapter's current unit of work. If success is false, the
+// journal is replayed at the adapter's finish level (LevelError by default).
+// The journal is then discarded and the adapter stops replaying, so that it
+// can be reused for another unit of work.
+func (a *Adapter) Finish(success bool) error {
+	var err error
+	if !success {
+		err = a.replay(a.newReplay(a.finishLevel, "unsuccessful finish"))
 	}
 
-	return nil
+	a.reset()
+	return err
 }
 
-func (a *Adapter) shouldJournal(level gomol.LogLevel) bool {
-	for _, l := range a.journaledLevels {
-		if l == level {
-			return true
-		}
+// triggerReplay replays the journal at the given level if a message logged
+// at that level should trigger a replay in the given order.
+func (a *Adapter) triggerReplay(level gomol.LogLevel, order TriggerOrder) error {
+	if a.trigger == nil || a.triggerOrder != order || !a.trigger(level) {
+		return nil
 	}
 
-	return false
+	return a.replay(a.newReplay(level, "triggered by "+level.String()+" message"))
 }
 
-func (a *Adapter) replayMessage(message *logMessage) error {
-	return a.base.LogWithTime(*a.replayingAt, message.ts, addAttr(message.attrs, message.level), message.msg, message.args...)
+// SetJournaledLevels changes the levels of messages which are journaled.
+// Messages already in the journal are retained.
+func (a *Adapter) SetJournaledLevels(levels ...gomol.LogLevel) {
+	atomic.StoreUint64(&a.journaledLevels, levelSet(levels...))
 }
 
-func addAttr(attrs *gomol.Attrs, level gomol.LogLevel) *gomol.Attrs {
-	if attrs == nil {
-		attrs = gomol.NewAttrs()
-	} else {
-		attrs = gomol.NewAttrsFromAttrs(attrs)
-	}
+// SetJournalThreshold changes the levels of messages which are journaled
+// to every level less severe than the given level. Messages already in the
+// journal are retained.
+func (a *Adapter) SetJournalThreshold(level gomol.LogLevel) {
+	atomic.StoreUint64(&a.journaledLevels, levelsBelow(level))
+}
 
-	return attrs.SetAttr(AttrReplay, level)
+func (a *Adapter) shouldJournal(level gomol.LogLevel) bool {
+	return containsLevel(atomic.LoadUint64(&a.journaledLevels), level)
 }
 
 func (a *Adapter) reset() {
-	a.journal = a.journal[:0]
-	a.replayingAt = nil
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.journal.reset()
+	a.sequence = 0
+	a.replaying = nil
 }
@@ -2,6 +2,7 @@ package gomolreplay
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/aphistic/gomol"
@@ -39,9 +40,9 @@ func (s *ReplaySuite) TestWhitelistLevelsAreJournaled(c *C) {
 	c.Assert(messages[3], Equals, "bnk")
 	c.Assert(messages[4], Equals, "qux")
 
-	c.Assert(len(adapter.journal), Equals, 2)
-	c.Assert(adapter.journal[0].msg, Equals, "bar")
-	c.Assert(adapter.journal[1].msg, Equals, "bnk")
+	c.Assert(adapter.journal.len(), Equals, 2)
+	c.Assert(adapter.journal.at(0).msg, Equals, "bar")
+	c.Assert(adapter.journal.at(1).msg, Equals, "bnk")
 }
 
 func (s *ReplaySuite) TestReplayJournal(c *C) {
@@ -339,6 +340,409 @@ func (s *ReplaySuite) TestReplayErrors(c *C) {
 	c.Assert(adapter.LogWithTime(gomol.LevelInfo, time.Now(), nil, "baz"), ErrorMatches, "Error 2")
 }
 
+func (s *ReplaySuite) TestReplayBoundedJournal(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		clock    = newMockClock(10000)
+		adapter  = NewConfiguredAdapter(logger, withClock(clock), WithJournaledLevels(gomol.LevelDebug), WithMaxEntries(2))
+		messages = []logArgs{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		if level == gomol.LevelWarning {
+			messages = append(messages, logArgs{level, attrs, msg, a})
+		}
+
+		return nil
+	}
+
+	for _, msg := range []string{"foo", "bar", "baz", "bnk", "qux"} {
+		adapter.Log(gomol.LevelDebug, nil, msg)
+		clock.advance(1000)
+	}
+
+	adapter.Replay(gomol.LevelWarning)
+
+	c.Assert(len(messages), Equals, 3)
+	c.Assert(messages[0].attrs.GetAttr(AttrEvicted), Equals, 3)
+	c.Assert(messages[0].a[0], Equals, 3)
+	c.Assert(messages[0].a[1], Equals, time.Unix(10, 0).Format(time.RFC3339Nano))
+	c.Assert(messages[0].a[2], Equals, time.Unix(12, 0).Format(time.RFC3339Nano))
+	c.Assert(messages[0].a[3], Equals, 2*time.Second)
+	c.Assert(messages[1].msg, Equals, "bnk")
+	c.Assert(messages[2].msg, Equals, "qux")
+}
+
+func (s *ReplaySuite) TestReplayTimeWindow(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		clock    = newMockClock(0)
+		adapter  = NewConfiguredAdapter(logger, withClock(clock), WithJournaledLevels(gomol.LevelDebug), WithMaxAge(time.Minute))
+		messages = []logArgs{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		if level == gomol.LevelWarning {
+			messages = append(messages, logArgs{level, attrs, msg, a})
+		}
+
+		return nil
+	}
+
+	adapter.Debug("foo")
+	clock.advance(30000)
+	adapter.Debug("bar")
+	clock.advance(20000)
+	adapter.LogWithTime(gomol.LevelDebug, time.Unix(35, 0), nil, "baz")
+	clock.advance(20000)
+	adapter.Debug("bnk")
+
+	// foo expired on the last log
+	c.Assert(adapter.journal.len(), Equals, 3)
+
+	clock.advance(30000)
+	adapter.Replay(gomol.LevelWarning)
+
+	// bar and baz expired by replay
+	c.Assert(len(messages), Equals, 2)
+	c.Assert(messages[0].attrs.GetAttr(AttrEvicted), Equals, 3)
+	c.Assert(messages[1].msg, Equals, "bnk")
+}
+
+func (s *ReplaySuite) TestBufferOnly(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		adapter  = NewConfiguredAdapter(logger, WithJournaledLevels(gomol.LevelDebug), WithBufferOnly())
+		messages = []logArgs{}
+	)
+
+	logger.log = func(level gomol.LogLevel, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Info("bar")
+	adapter.Debug("baz")
+
+	c.Assert(len(messages), Equals, 1)
+	c.Assert(messages[0].level, Equals, gomol.LevelInfo)
+	c.Assert(messages[0].msg, Equals, "bar")
+
+	adapter.Replay(gomol.LevelWarning)
+	adapter.Debug("bnk")
+
+	c.Assert(len(messages), Equals, 4)
+	c.Assert(messages[1].level, Equals, gomol.LevelWarning)
+	c.Assert(messages[2].level, Equals, gomol.LevelWarning)
+	c.Assert(messages[3].level, Equals, gomol.LevelWarning)
+
+	for i, msg := range []string{"bar", "foo", "baz", "bnk"} {
+		c.Assert(messages[i].msg, Equals, msg)
+	}
+}
+
+func (s *ReplaySuite) TestBufferOnlyOriginalLevel(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		adapter  = NewConfiguredAdapter(logger, WithJournaledLevels(gomol.LevelDebug, gomol.LevelInfo), WithBufferOnly(), WithOriginalLevelReplay())
+		messages = []logArgs{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Info("bar")
+	c.Assert(len(messages), Equals, 0)
+
+	adapter.Replay(gomol.LevelWarning)
+	c.Assert(len(messages), Equals, 2)
+	c.Assert(messages[0].level, Equals, gomol.LevelDebug)
+	c.Assert(messages[1].level, Equals, gomol.LevelInfo)
+	c.Assert(messages[0].attrs.GetAttr(AttrReplay), Equals, gomol.LevelDebug)
+	c.Assert(messages[1].attrs.GetAttr(AttrReplay), Equals, gomol.LevelInfo)
+}
+
+func (s *ReplaySuite) TestBufferOnlyDiscard(c *C) {
+	var (
+		logger  = newDefaultMockLogger()
+		adapter = NewConfiguredAdapter(logger, WithJournaledLevels(gomol.LevelDebug), WithBufferOnly())
+		calls   = 0
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		calls++
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Debug("bar")
+	adapter.Discard()
+	adapter.Replay(gomol.LevelWarning)
+	c.Assert(calls, Equals, 0)
+}
+
+func (s *ReplaySuite) TestTriggerLevel(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		adapter  = NewConfiguredAdapter(logger, WithJournaledLevels(gomol.LevelDebug), WithTriggerLevel(gomol.LevelError))
+		messages = []logArgs{}
+	)
+
+	logger.log = func(level gomol.LogLevel, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Debug("bar")
+	adapter.Warning("baz")
+	c.Assert(len(messages), Equals, 3)
+
+	adapter.Fatal("bnk")
+	c.Assert(len(messages), Equals, 6)
+	c.Assert(messages[3].level, Equals, gomol.LevelFatal)
+	c.Assert(messages[4].level, Equals, gomol.LevelFatal)
+	c.Assert(messages[5].level, Equals, gomol.LevelFatal)
+
+	for i, msg := range []string{"foo", "bar", "baz", "foo", "bar", "bnk"} {
+		c.Assert(messages[i].msg, Equals, msg)
+	}
+}
+
+func (s *ReplaySuite) TestTriggerLevelsAfter(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		messages = []logArgs{}
+		adapter  = NewConfiguredAdapter(
+			logger,
+			WithJournaledLevels(gomol.LevelDebug),
+			WithTriggerLevels(gomol.LevelWarning),
+			WithTriggerOrder(ReplayAfterTrigger),
+		)
+	)
+
+	logger.log = func(level gomol.LogLevel, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Error("bar")
+	c.Assert(len(messages), Equals, 2)
+
+	adapter.LogWithTime(gomol.LevelWarning, time.Now(), nil, "baz")
+	c.Assert(len(messages), Equals, 4)
+	c.Assert(messages[2].level, Equals, gomol.LevelWarning)
+	c.Assert(messages[2].msg, Equals, "baz")
+	c.Assert(messages[3].level, Equals, gomol.LevelWarning)
+	c.Assert(messages[3].msg, Equals, "foo")
+}
+
+func (s *ReplaySuite) TestReplayTo(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		target   = newDefaultMockLogger()
+		adapter  = NewAdapter(logger, gomol.LevelDebug)
+		messages = []logArgs{}
+		replayed = []logArgs{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	target.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		replayed = append(replayed, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Debug("bar")
+	adapter.ReplayTo(target, gomol.LevelWarning)
+	adapter.Debug("baz")
+
+	c.Assert(len(messages), Equals, 3)
+	c.Assert(len(replayed), Equals, 3)
+
+	for i, msg := range []string{"foo", "bar", "baz"} {
+		c.Assert(messages[i].level, Equals, gomol.LevelDebug)
+		c.Assert(messages[i].msg, Equals, msg)
+		c.Assert(replayed[i].level, Equals, gomol.LevelWarning)
+		c.Assert(replayed[i].msg, Equals, msg)
+	}
+}
+
+func (s *ReplaySuite) TestReplayToAfterReplay(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		target   = newDefaultMockLogger()
+		adapter  = NewAdapter(logger, gomol.LevelDebug)
+		replayed = []string{}
+	)
+
+	target.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		replayed = append(replayed, msg)
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Debug("bar")
+	adapter.Replay(gomol.LevelWarning)
+
+	// The target has not received the journal, so the replay is not redundant
+	adapter.ReplayTo(target, gomol.LevelDebug)
+	adapter.Debug("baz")
+
+	c.Assert(replayed, DeepEquals, []string{"foo", "bar", "baz"})
+}
+
+func (s *ReplaySuite) TestReplayTarget(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		target   = newDefaultMockLogger()
+		adapter  = NewConfiguredAdapter(logger, WithJournaledLevels(gomol.LevelDebug), WithReplayTarget(target))
+		messages = 0
+		replayed = 0
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages++
+		return nil
+	}
+
+	target.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		replayed++
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Debug("bar")
+	adapter.Replay(gomol.LevelWarning)
+	adapter.Debug("baz")
+
+	c.Assert(messages, Equals, 3)
+	c.Assert(replayed, Equals, 3)
+}
+
+func (s *ReplaySuite) TestDiscardReleasesBudget(c *C) {
+	var (
+		logger  = newDefaultMockLogger()
+		budget  = NewBudget(1 << 20)
+		adapter = NewConfiguredAdapter(logger, WithJournaledLevels(gomol.LevelDebug), WithSharedBudget(budget))
+	)
+
+	adapter.Debug("foo")
+	adapter.Debugf("bar %s", "baz")
+	c.Assert(budget.Used(), Equals, int64(2*messageOverhead+3+6+3))
+
+	adapter.Discard()
+	c.Assert(budget.Used(), Equals, int64(0))
+	c.Assert(adapter.journal.len(), Equals, 0)
+}
+
+func (s *ReplaySuite) TestConcurrentLogAndReplay(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		adapter  = NewAdapter(logger, gomol.LevelDebug)
+		mutex    = sync.Mutex{}
+		replayed = map[string]int{}
+		wg       = sync.WaitGroup{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		if level == gomol.LevelWarning {
+			mutex.Lock()
+			replayed[fmt.Sprintf(msg, a...)]++
+			mutex.Unlock()
+		}
+
+		return nil
+	}
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < 200; j++ {
+				adapter.Debugf("%d-%d", i, j)
+			}
+		}(i)
+	}
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		adapter.Replay(gomol.LevelWarning)
+	}()
+
+	wg.Wait()
+
+	c.Assert(adapter.journal.len(), Equals, 8*200)
+	c.Assert(len(replayed), Equals, 8*200)
+
+	for msg, count := range replayed {
+		c.Assert(count, Equals, 1, Commentf("message %s", msg))
+	}
+}
+
+func (s *ReplaySuite) TestConcurrentReplayAndReset(c *C) {
+	var (
+		logger  = newDefaultMockLogger()
+		adapter = NewAdapter(logger, gomol.LevelDebug, gomol.LevelInfo)
+		wg      = sync.WaitGroup{}
+	)
+
+	for i := 0; i < 4; i++ {
+		wg.Add(3)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				adapter.Info("foo")
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 10; j++ {
+				adapter.Replay(gomol.LevelError)
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+			adapter.reset()
+		}()
+	}
+
+	wg.Wait()
+}
+
 func (s *ReplaySuite) TestShutdownLoggers(c *C) {
 	var (
 		logger  = newDefaultMockLogger()
@@ -0,0 +1,39 @@
+package gomolreplay
+
+import "sync/atomic"
+
+// Budget is a byte budget which can be shared by many adapters so that
+// the total size of all of their journals is bounded.
+type Budget struct {
+	used     int64
+	maxBytes int64
+}
+
+// NewBudget creates a Budget which allows at most maxBytes bytes to be
+// held by the journals of all adapters sharing it.
+func NewBudget(maxBytes int64) *Budget {
+	return &Budget{maxBytes: maxBytes}
+}
+
+// Used returns the number of bytes currently held by adapters sharing
+// this budget.
+func (b *Budget) Used() int64 {
+	return atomic.LoadInt64(&b.used)
+}
+
+func (b *Budget) acquire(n int64) bool {
+	for {
+		used := atomic.LoadInt64(&b.used)
+		if used+n > b.maxBytes {
+			return false
+		}
+
+		if atomic.CompareAndSwapInt64(&b.used, used, used+n) {
+			return true
+		}
+	}
+}
+
+func (b *Budget) release(n int64) {
+	atomic.AddInt64(&b.used, -n)
+}
@@ -0,0 +1,49 @@
+package gomolreplay
+
+import (
+	"sync"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *ReplaySuite) TestBudgetAcquireRelease(c *C) {
+	budget := NewBudget(100)
+
+	c.Assert(budget.acquire(60), Equals, true)
+	c.Assert(budget.acquire(60), Equals, false)
+	c.Assert(budget.acquire(40), Equals, true)
+	c.Assert(budget.Used(), Equals, int64(100))
+
+	budget.release(60)
+	c.Assert(budget.Used(), Equals, int64(40))
+	c.Assert(budget.acquire(60), Equals, true)
+}
+
+func (s *ReplaySuite) TestBudgetConcurrentAcquire(c *C) {
+	var (
+		budget   = NewBudget(1000)
+		wg       = sync.WaitGroup{}
+		mutex    = sync.Mutex{}
+		acquired = 0
+	)
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 10; j++ {
+				if budget.acquire(3) {
+					mutex.Lock()
+					acquired++
+					mutex.Unlock()
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	c.Assert(acquired, Equals, 333)
+	c.Assert(budget.Used(), Equals, int64(999))
+}
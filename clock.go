@@ -5,11 +5,33 @@ import "time"
 type (
 	clock interface {
 		Now() time.Time
+		NewTimer(d time.Duration) timer
+	}
+
+	timer interface {
+		Chan() <-chan time.Time
+		Stop() bool
 	}
 
 	realClock struct{}
+
+	realTimer struct {
+		timer *time.Timer
+	}
 )
 
 func (rc *realClock) Now() time.Time {
 	return time.Now()
 }
+
+func (rc *realClock) NewTimer(d time.Duration) timer {
+	return &realTimer{time.NewTimer(d)}
+}
+
+func (rt *realTimer) Chan() <-chan time.Time {
+	return rt.timer.C
+}
+
+func (rt *realTimer) Stop() bool {
+	return rt.timer.Stop()
+}
@@ -0,0 +1,231 @@
+package gomolreplay
+
+import (
+	"time"
+
+	"github.com/aphistic/gomol"
+)
+
+// ConfigFunc is a function used to configure an Adapter.
+type ConfigFunc func(*Adapter)
+
+// WithJournaledLevels sets the levels of messages which are journaled
+// and can later be replayed.
+func WithJournaledLevels(levels ...gomol.LogLevel) ConfigFunc {
+	return func(a *Adapter) { a.journaledLevels = levelSet(levels...) }
+}
+
+// WithJournalThreshold causes messages logged at every level less severe
+// than the given level to be journaled. For example, a threshold of
+// LevelWarning journals debug and info messages.
+func WithJournalThreshold(level gomol.LogLevel) ConfigFunc {
+	return func(a *Adapter) { a.journaledLevels = levelsBelow(level) }
+}
+
+// WithRules adds rules which determine whether a message is journaled and
+// whether it is forwarded to the wrapped logger. Rules are applied in the
+// order they are added, and the first rule which applies to a message takes
+// precedence over the journaled levels.
+func WithRules(rules ...Rule) ConfigFunc {
+	return func(a *Adapter) { a.rules = append(a.rules, rules...) }
+}
+
+// WithMaxEntries limits the number of messages held in the journal. Once
+// the limit is reached, the oldest message is evicted to make room for
+// each new message. A value of zero (the default) means no limit.
+func WithMaxEntries(maxEntries int) ConfigFunc {
+	return func(a *Adapter) { a.journal.maxEntries = maxEntries }
+}
+
+// WithMaxBytes limits the estimated memory size of the messages held in
+// the journal. A value of zero (the default) means no limit.
+func WithMaxBytes(maxBytes int64) ConfigFunc {
+	return func(a *Adapter) { a.journal.maxBytes = maxBytes }
+}
+
+// WithSharedBudget limits the estimated memory size of the messages held
+// in the journal of every adapter sharing the given budget. Bytes are
+// returned to the budget as messages are evicted or when the adapter's
+// journal is discarded.
+func WithSharedBudget(budget *Budget) ConfigFunc {
+	return func(a *Adapter) { a.journal.budget = budget }
+}
+
+// WithOverflowBehavior sets how the journal makes room for a message which
+// does not fit into its byte budget. The default is OverflowDropOldest.
+func WithOverflowBehavior(overflow OverflowBehavior) ConfigFunc {
+	return func(a *Adapter) { a.journal.overflow = overflow }
+}
+
+// WithEvictionPolicy sets which message is evicted when the journal is
+// full. The default is EvictOldest.
+func WithEvictionPolicy(eviction EvictionPolicy) ConfigFunc {
+	return func(a *Adapter) { a.journal.eviction = eviction }
+}
+
+// WithMaxAge limits the journal to messages whose timestamp is within the
+// given duration of the current time. Expired messages are evicted lazily
+// when a message is logged and when the journal is replayed. A value of
+// zero (the default) means no limit.
+func WithMaxAge(maxAge time.Duration) ConfigFunc {
+	return func(a *Adapter) { a.journal.maxAge = maxAge }
+}
+
+// WithBufferOnly prevents messages at journaled levels from being sent to
+// the wrapped logger when they are logged. Such messages are sent only when
+// the journal is replayed, and are never sent if the journal is discarded
+// without being replayed.
+func WithBufferOnly() ConfigFunc {
+	return func(a *Adapter) { a.bufferOnly = true }
+}
+
+// WithOriginalLevelReplay causes replayed messages to be sent at the level
+// at which they were originally logged instead of the level passed to Replay.
+// This is intended to be used along with WithBufferOnly.
+func WithOriginalLevelReplay() ConfigFunc {
+	return func(a *Adapter) { a.originalLevel = true }
+}
+
+// WithTriggerLevel causes the journal to be replayed automatically when a
+// message is logged at the given level or at a more severe level. The
+// journal is replayed at the level of the triggering message.
+func WithTriggerLevel(level gomol.LogLevel) ConfigFunc {
+	return func(a *Adapter) {
+		a.trigger = func(l gomol.LogLevel) bool { return l <= level }
+	}
+}
+
+// WithTriggerLevels causes the journal to be replayed automatically when a
+// message is logged at one of the given levels. The journal is replayed at
+// the level of the triggering message.
+func WithTriggerLevels(levels ...gomol.LogLevel) ConfigFunc {
+	return func(a *Adapter) {
+		a.trigger = func(l gomol.LogLevel) bool {
+			for _, level := range levels {
+				if l == level {
+					return true
+				}
+			}
+
+			return false
+		}
+	}
+}
+
+// WithTriggerOrder sets whether an automatically triggered replay happens
+// before or after the triggering message is logged. The default order is
+// ReplayBeforeTrigger.
+func WithTriggerOrder(order TriggerOrder) ConfigFunc {
+	return func(a *Adapter) { a.triggerOrder = order }
+}
+
+// WithReplayTarget sets the logger to which replayed messages are sent by
+// Replay. By default, replayed messages are sent to the wrapped logger.
+func WithReplayTarget(target gomol.WrappableLogger) ConfigFunc {
+	return func(a *Adapter) { a.replayTarget = target }
+}
+
+// WithOneShotReplay causes every replay of the adapter (including those
+// triggered automatically) to behave like ReplayOnce: the journal is
+// discarded after it is replayed, and future messages are not replayed
+// immediately.
+func WithOneShotReplay() ConfigFunc {
+	return func(a *Adapter) { a.oneShot = true }
+}
+
+// WithEscalationMarkers changes how a replay at a more severe level is handled
+// when the adapter is already replaying. Instead of replaying the entire journal
+// again, a single marker message is sent which refers to the earlier replay by
+// its identifier (see AttrEscalatedFrom). Future messages are replayed at the
+// new level. Use ReplayFull to replay the entire journal regardless.
+func WithEscalationMarkers() ConfigFunc {
+	return func(a *Adapter) { a.escalationMarkers = true }
+}
+
+// WithEagerFormatting causes each journaled message to be formatted when it
+// is logged rather than when it is sent, so that a replayed message reflects
+// the state of its arguments at the time it was logged even if they are later
+// mutated. Attributes are copied, and attribute values which are not scalars
+// are replaced with their formatted text. This costs a formatting operation
+// for every journaled message, even those which are never replayed.
+func WithEagerFormatting() ConfigFunc {
+	return func(a *Adapter) { a.eagerFormatting = true }
+}
+
+// WithFinishLevel sets the level at which the journal is replayed when
+// Finish is called with an unsuccessful result. The default is LevelError.
+func WithFinishLevel(level gomol.LogLevel) ConfigFunc {
+	return func(a *Adapter) { a.finishLevel = level }
+}
+
+// WithSwallowPanics causes RecoverAndReplay to stop a recovered panic
+// instead of resuming it.
+func WithSwallowPanics() ConfigFunc {
+	return func(a *Adapter) { a.swallowPanics = true }
+}
+
+// WithReplayOnDie causes Die, Dief, and Diem to replay the journal at the
+// given level (generally LevelFatal) before logging the fatal message.
+func WithReplayOnDie(level gomol.LogLevel) ConfigFunc {
+	return func(a *Adapter) { a.dieReplayLevel = &level }
+}
+
+// WithPreExitHook registers a function which is called by Die, Dief, and
+// Diem after the fatal message is logged but before the wrapped logger is
+// shut down and the application exits. Hooks are called in the order they
+// were registered.
+func WithPreExitHook(hook func()) ConfigFunc {
+	return func(a *Adapter) { a.preExitHooks = append(a.preExitHooks, hook) }
+}
+
+// WithReplayLevelAttr sets the key of the attribute which holds the original
+// level of a replayed message. The default is AttrReplay. An empty key omits
+// the attribute.
+func WithReplayLevelAttr(key string) ConfigFunc {
+	return func(a *Adapter) { a.attrKeys.level = key }
+}
+
+// WithReplayIDAttr assigns an attribute with the given key (e.g. AttrReplayID)
+// to each replayed message. Its value is a random identifier shared by all
+// messages sent as part of the same replay.
+func WithReplayIDAttr(key string) ConfigFunc {
+	return func(a *Adapter) { a.attrKeys.id = key }
+}
+
+// WithSequenceAttr assigns an attribute with the given key (e.g. AttrSequence)
+// to each replayed message. Its value is the position of the message among all
+// messages journaled by the adapter, starting at one. Gaps in the sequence
+// indicate evicted messages.
+func WithSequenceAttr(key string) ConfigFunc {
+	return func(a *Adapter) { a.attrKeys.sequence = key }
+}
+
+// WithJournalSizeAttr assigns an attribute with the given key (e.g.
+// AttrJournalSize) to each replayed message. Its value is the number of
+// messages in the journal at the time of the replay.
+func WithJournalSizeAttr(key string) ConfigFunc {
+	return func(a *Adapter) { a.attrKeys.journalSize = key }
+}
+
+// WithReasonAttr assigns an attribute with the given key (e.g. AttrReason)
+// to each replayed message. Its value describes why the journal was replayed
+// (e.g. the reason passed to ReplayBecause). The attribute is omitted for
+// replays without a reason.
+func WithReasonAttr(key string) ConfigFunc {
+	return func(a *Adapter) { a.attrKeys.reason = key }
+}
+
+// WithReplayTimestamps causes replayed messages to be sent with the time of
+// the replay instead of the time the message was logged. The original time is
+// assigned to an attribute with the given key (e.g. AttrOriginalTime).
+func WithReplayTimestamps(key string) ConfigFunc {
+	return func(a *Adapter) { a.attrKeys.originalTime = key }
+}
+
+func withReplayID(replayID func() string) ConfigFunc {
+	return func(a *Adapter) { a.replayID = replayID }
+}
+
+func withClock(clock clock) ConfigFunc {
+	return func(a *Adapter) { a.clock = clock }
+}
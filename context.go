@@ -0,0 +1,51 @@
+package gomolreplay
+
+import (
+	"context"
+
+	"github.com/aphistic/gomol"
+)
+
+type contextKey struct{}
+
+// NewContext returns a copy of the given context which carries the
+// given adapter.
+func NewContext(ctx context.Context, a *Adapter) context.Context {
+	return context.WithValue(ctx, contextKey{}, a)
+}
+
+// FromContext returns the adapter carried by the given context, if any.
+func FromContext(ctx context.Context) (*Adapter, bool) {
+	a, ok := ctx.Value(contextKey{}).(*Adapter)
+	return a, ok && a != nil
+}
+
+// LoggerFromContext returns the adapter carried by the given context. If
+// the context does not carry an adapter, the fallback logger is returned.
+func LoggerFromContext(ctx context.Context, fallback gomol.WrappableLogger) gomol.WrappableLogger {
+	if a, ok := FromContext(ctx); ok {
+		return a
+	}
+
+	return fallback
+}
+
+// ReplayOnDeadline starts a watchdog which replays the journal at the given
+// level if the given context is cancelled because its deadline is exceeded.
+// The watchdog should be stopped once the context is no longer in use. Errors
+// from the wrapped logger during the replay are discarded.
+func (a *Adapter) ReplayOnDeadline(ctx context.Context, level gomol.LogLevel) *Watchdog {
+	wait := func(stop <-chan struct{}) bool {
+		select {
+		case <-ctx.Done():
+			return ctx.Err() == context.DeadlineExceeded
+
+		case <-stop:
+			return false
+		}
+	}
+
+	return startWatchdog(wait, func() {
+		a.replay(a.newReplay(level, "context deadline exceeded"))
+	})
+}
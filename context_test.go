@@ -0,0 +1,87 @@
+package gomolreplay
+
+import (
+	"context"
+	"time"
+
+	"github.com/aphistic/gomol"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *ReplaySuite) TestContext(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		fallback = newDefaultMockLogger()
+		adapter  = NewAdapter(logger)
+	)
+
+	_, ok := FromContext(context.Background())
+	c.Assert(ok, Equals, false)
+	c.Assert(LoggerFromContext(context.Background(), fallback), Equals, fallback)
+
+	ctx := NewContext(context.Background(), adapter)
+	value, ok := FromContext(ctx)
+	c.Assert(ok, Equals, true)
+	c.Assert(value, Equals, adapter)
+	c.Assert(LoggerFromContext(ctx, fallback), Equals, adapter)
+
+	ctx = NewContext(context.Background(), nil)
+	c.Assert(LoggerFromContext(ctx, fallback), Equals, fallback)
+}
+
+func (s *ReplaySuite) TestReplayOnDeadline(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		adapter  = NewAdapter(logger, gomol.LevelDebug)
+		messages = make(chan logArgs, 10)
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages <- logArgs{level, attrs, msg, a}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	watchdog := adapter.ReplayOnDeadline(ctx, gomol.LevelWarning)
+	adapter.Debug("foo")
+	c.Assert((<-messages).level, Equals, gomol.LevelDebug)
+
+	<-watchdog.done
+	c.Assert(watchdog.Stop(), Equals, false)
+
+	message := <-messages
+	c.Assert(message.level, Equals, gomol.LevelWarning)
+	c.Assert(message.msg, Equals, "foo")
+}
+
+func (s *ReplaySuite) TestReplayOnDeadlineCancelled(c *C) {
+	var (
+		logger  = newDefaultMockLogger()
+		adapter = NewAdapter(logger, gomol.LevelDebug)
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	watchdog := adapter.ReplayOnDeadline(ctx, gomol.LevelWarning)
+	cancel()
+
+	<-watchdog.done
+	c.Assert(watchdog.Stop(), Equals, true)
+	c.Assert(adapter.replaying, IsNil)
+}
+
+func (s *ReplaySuite) TestReplayOnDeadlineStopped(c *C) {
+	var (
+		logger  = newDefaultMockLogger()
+		adapter = NewAdapter(logger, gomol.LevelDebug)
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	watchdog := adapter.ReplayOnDeadline(ctx, gomol.LevelWarning)
+	c.Assert(watchdog.Stop(), Equals, true)
+	c.Assert(adapter.replaying, IsNil)
+}
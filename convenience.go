@@ -198,21 +198,40 @@ func (a *Adapter) Fatalm(m *gomol.Attrs, msg string, args ...interface{}) error
 // Die will log a message using Fatal, call ShutdownLoggers and then exit the application with the provided exit code.
 // This function is not subject to rollup and is always sent to the wrapped logger.
 func (a *Adapter) Die(exitCode int, msg string) {
+	a.replayBeforeDeath()
 	a.Log(gomol.LevelFatal, nil, msg)
-	a.base.ShutdownLoggers()
-	curExiter.Exit(exitCode)
+	a.die(exitCode)
 }
 
 // Dief will log a message using Fatalf, call ShutdownLoggers and then exit the application with the provided exit code.
 func (a *Adapter) Dief(exitCode int, msg string, args ...interface{}) {
+	a.replayBeforeDeath()
 	a.Log(gomol.LevelFatal, nil, msg, args...)
-	a.base.ShutdownLoggers()
-	curExiter.Exit(exitCode)
+	a.die(exitCode)
 }
 
 // Diem will log a message using Fatalm, call ShutdownLoggers and then exit the application with the provided exit code.
 func (a *Adapter) Diem(exitCode int, m *gomol.Attrs, msg string, args ...interface{}) {
+	a.replayBeforeDeath()
 	a.Log(gomol.LevelFatal, m, msg, args...)
+	a.die(exitCode)
+}
+
+// replayBeforeDeath replays the journal if the adapter was created with
+// the WithReplayOnDie config function.
+func (a *Adapter) replayBeforeDeath() {
+	if a.dieReplayLevel != nil {
+		a.replay(a.newReplay(*a.dieReplayLevel, "die"))
+	}
+}
+
+// die calls the adapter's pre-exit hooks in the order they were registered,
+// calls ShutdownLoggers, and then exits the application.
+func (a *Adapter) die(exitCode int) {
+	for _, hook := range a.preExitHooks {
+		hook()
+	}
+
 	a.base.ShutdownLoggers()
 	curExiter.Exit(exitCode)
 }
@@ -94,6 +94,57 @@ func (s *ReplaySuite) TestDieEndsProcessAfterImmediateReplay(c *C) {
 	}
 }
 
+func (s *ReplaySuite) TestDieReplaysJournal(c *C) {
+	var (
+		messages  = []logArgs{}
+		hookCalls = []bool{}
+		shutdown  = false
+
+		exiter  = &testExiter{}
+		logger  = newDefaultMockLogger()
+		adapter = NewConfiguredAdapter(
+			logger,
+			WithJournaledLevels(gomol.LevelDebug),
+			WithReplayOnDie(gomol.LevelFatal),
+			WithPreExitHook(func() { hookCalls = append(hookCalls, shutdown || exiter.exited) }),
+			WithPreExitHook(func() { hookCalls = append(hookCalls, shutdown || exiter.exited) }),
+		)
+	)
+
+	setExiter(exiter)
+
+	logger.log = func(level gomol.LogLevel, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	logger.shutdownLoggers = func() error {
+		shutdown = true
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Debug("bar")
+	adapter.Dief(1, "baz %d", 42)
+
+	c.Assert(exiter.exited, Equals, true)
+	c.Assert(exiter.code, Equals, 1)
+	c.Assert(hookCalls, DeepEquals, []bool{false, false})
+
+	c.Assert(len(messages), Equals, 5)
+	c.Assert(messages[2].level, Equals, gomol.LevelFatal)
+	c.Assert(messages[2].msg, Equals, "foo")
+	c.Assert(messages[3].level, Equals, gomol.LevelFatal)
+	c.Assert(messages[3].msg, Equals, "bar")
+	c.Assert(messages[4].level, Equals, gomol.LevelFatal)
+	c.Assert(messages[4].msg, Equals, "baz %d")
+}
+
 func (s *ReplaySuite) TestConvenienceMethods(c *C) {
 	var (
 		logger   = newDefaultMockLogger()
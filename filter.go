@@ -0,0 +1,82 @@
+package gomolreplay
+
+import (
+	"reflect"
+	"regexp"
+	"time"
+
+	"github.com/aphistic/gomol"
+)
+
+// Filter selects the journaled messages sent by ReplayFiltered from the
+// level and time at which the message was logged, its message template
+// (the format string, not the formatted message), and its attributes
+// (which may be nil).
+type Filter func(level gomol.LogLevel, ts time.Time, msg string, attrs *gomol.Attrs) bool
+
+// ReplayFiltered is similar to ReplayOnce, but only the journaled messages
+// selected by the given filter are replayed. The journal is retained, and
+// future messages are not replayed immediately.
+func (a *Adapter) ReplayFiltered(level gomol.LogLevel, filter Filter) error {
+	replaying := a.newReplay(level, "")
+	replaying.filter = filter
+	return a.replay(replaying)
+}
+
+// LevelFilter creates a filter which selects messages logged at the given
+// level or a more severe level.
+func LevelFilter(level gomol.LogLevel) Filter {
+	return func(l gomol.LogLevel, ts time.Time, msg string, attrs *gomol.Attrs) bool {
+		return l <= level
+	}
+}
+
+// TimeFilter creates a filter which selects messages logged at or after
+// from and before to. A zero time leaves that end of the range open.
+func TimeFilter(from, to time.Time) Filter {
+	return func(level gomol.LogLevel, ts time.Time, msg string, attrs *gomol.Attrs) bool {
+		return (from.IsZero() || !ts.Before(from)) && (to.IsZero() || ts.Before(to))
+	}
+}
+
+// AttrFilter creates a filter which selects messages with an attribute with
+// the given key and value.
+func AttrFilter(key string, value interface{}) Filter {
+	return func(level gomol.LogLevel, ts time.Time, msg string, attrs *gomol.Attrs) bool {
+		return attrs != nil && reflect.DeepEqual(attrs.GetAttr(key), value)
+	}
+}
+
+// MessageFilter creates a filter which selects messages whose template
+// matches the given pattern.
+func MessageFilter(pattern *regexp.Regexp) Filter {
+	return func(level gomol.LogLevel, ts time.Time, msg string, attrs *gomol.Attrs) bool {
+		return pattern.MatchString(msg)
+	}
+}
+
+// AllFilters creates a filter which selects messages selected by every one
+// of the given filters.
+func AllFilters(filters ...Filter) Filter {
+	return func(level gomol.LogLevel, ts time.Time, msg string, attrs *gomol.Attrs) bool {
+		for _, filter := range filters {
+			if !filter(level, ts, msg, attrs) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// filterMessages returns the messages selected by the given filter.
+func filterMessages(messages []*logMessage, filter Filter) []*logMessage {
+	filtered := make([]*logMessage, 0, len(messages))
+	for _, message := range messages {
+		if filter(message.level, message.ts, message.template(), message.attrs) {
+			filtered = append(filtered, message)
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,115 @@
+package gomolreplay
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/aphistic/gomol"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *ReplaySuite) TestReplayFiltered(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		adapter  = NewConfiguredAdapter(logger, WithJournaledLevels(gomol.LevelDebug, gomol.LevelInfo), WithBufferOnly())
+		messages = []logArgs{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	adapter.Debugm(gomol.NewAttrsFromMap(map[string]interface{}{"component": "db"}), "foo")
+	adapter.Info("bar")
+	adapter.Infom(gomol.NewAttrsFromMap(map[string]interface{}{"component": "db"}), "baz")
+
+	adapter.ReplayFiltered(gomol.LevelWarning, AttrFilter("component", "db"))
+	c.Assert(len(messages), Equals, 2)
+	c.Assert(messages[0].msg, Equals, "foo")
+	c.Assert(messages[1].msg, Equals, "baz")
+
+	adapter.ReplayFiltered(gomol.LevelWarning, LevelFilter(gomol.LevelInfo))
+	c.Assert(len(messages), Equals, 4)
+	c.Assert(messages[2].msg, Equals, "bar")
+	c.Assert(messages[3].msg, Equals, "baz")
+
+	// The journal is retained and future messages are not replayed
+	adapter.Debug("bnk")
+	c.Assert(len(messages), Equals, 4)
+	c.Assert(adapter.journal.len(), Equals, 4)
+
+	adapter.Replay(gomol.LevelError)
+	c.Assert(len(messages), Equals, 8)
+
+	for i, msg := range []string{"foo", "bar", "baz", "bnk"} {
+		c.Assert(messages[4+i].msg, Equals, msg)
+		c.Assert(messages[4+i].level, Equals, gomol.LevelError)
+	}
+}
+
+func (s *ReplaySuite) TestReplayFilteredBracket(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		adapter  = NewConfiguredAdapter(logger, WithJournaledLevels(gomol.LevelDebug), WithBufferOnly(), WithJournalSizeAttr(AttrJournalSize))
+		messages = []logArgs{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Debug("bar")
+	adapter.ReplayFiltered(gomol.LevelWarning, MessageFilter(regexp.MustCompile("^b")))
+
+	c.Assert(len(messages), Equals, 1)
+	c.Assert(messages[0].msg, Equals, "bar")
+	c.Assert(messages[0].attrs.GetAttr(AttrJournalSize), Equals, 2)
+}
+
+func (s *ReplaySuite) TestReplayFilteredMatchesTemplate(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		adapter  = NewConfiguredAdapter(logger, WithJournaledLevels(gomol.LevelDebug), WithBufferOnly(), WithEagerFormatting())
+		messages = []logArgs{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	adapter.Debugf("query %s", "foo")
+	adapter.Debugf("request %s", "bar")
+	adapter.ReplayFiltered(gomol.LevelWarning, MessageFilter(regexp.MustCompile("^query %s$")))
+
+	c.Assert(len(messages), Equals, 1)
+	c.Assert(messages[0].a, DeepEquals, []interface{}{"query foo"})
+}
+
+func (s *ReplaySuite) TestTimeFilter(c *C) {
+	var (
+		from   = time.Unix(10, 0)
+		to     = time.Unix(20, 0)
+		filter = TimeFilter(from, to)
+	)
+
+	c.Assert(filter(gomol.LevelDebug, time.Unix(9, 0), "", nil), Equals, false)
+	c.Assert(filter(gomol.LevelDebug, time.Unix(10, 0), "", nil), Equals, true)
+	c.Assert(filter(gomol.LevelDebug, time.Unix(19, 0), "", nil), Equals, true)
+	c.Assert(filter(gomol.LevelDebug, time.Unix(20, 0), "", nil), Equals, false)
+	c.Assert(TimeFilter(time.Time{}, to)(gomol.LevelDebug, time.Unix(0, 0), "", nil), Equals, true)
+	c.Assert(TimeFilter(from, time.Time{})(gomol.LevelDebug, time.Unix(100, 0), "", nil), Equals, true)
+}
+
+func (s *ReplaySuite) TestAllFilters(c *C) {
+	filter := AllFilters(LevelFilter(gomol.LevelInfo), AttrFilter("component", "db"))
+	attrs := gomol.NewAttrsFromMap(map[string]interface{}{"component": "db"})
+
+	c.Assert(filter(gomol.LevelInfo, time.Time{}, "", attrs), Equals, true)
+	c.Assert(filter(gomol.LevelDebug, time.Time{}, "", attrs), Equals, false)
+	c.Assert(filter(gomol.LevelInfo, time.Time{}, "", nil), Equals, false)
+}
@@ -0,0 +1,151 @@
+// Package grpcreplay provides gRPC server interceptors which create a
+// replay adapter for each RPC and replay its journal when the RPC fails
+// or is served slowly.
+package grpcreplay
+
+import (
+	"context"
+	"time"
+
+	"github.com/aphistic/gomol"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	replay "github.com/efritz/gomol-replay"
+)
+
+type (
+	// ConfigFunc is a function used to configure an interceptor.
+	ConfigFunc func(*interceptor)
+
+	interceptor struct {
+		logger           gomol.WrappableLogger
+		configs          []replay.ConfigFunc
+		codes            map[codes.Code]struct{}
+		latencyThreshold time.Duration
+		now              func() time.Time
+	}
+
+	wrappedStream struct {
+		grpc.ServerStream
+		ctx context.Context
+	}
+)
+
+// DefaultReplayCodes are the status codes which cause the journal to be
+// replayed unless overridden with WithReplayCodes.
+var DefaultReplayCodes = []codes.Code{
+	codes.Unknown,
+	codes.Internal,
+	codes.Unavailable,
+	codes.DeadlineExceeded,
+	codes.DataLoss,
+}
+
+// UnaryServerInterceptor returns an interceptor which serves each unary RPC
+// with a new Adapter wrapping the given logger. The adapter is stored in the
+// RPC context and can be retrieved by the handler with replay.FromContext.
+// The journal is replayed (as if by calling Finish with an unsuccessful
+// result) if the handler returns an error with one of the replay codes,
+// panics, or exceeds the configured latency threshold. Otherwise, the
+// journal is discarded.
+func UnaryServerInterceptor(logger gomol.WrappableLogger, configs ...ConfigFunc) grpc.UnaryServerInterceptor {
+	i := newInterceptor(logger, configs...)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		adapter, start := i.start()
+		defer func() { i.finish(adapter, start, err, recover()) }()
+
+		return handler(replay.NewContext(ctx, adapter), req)
+	}
+}
+
+// StreamServerInterceptor returns an interceptor which serves each streaming
+// RPC with a new Adapter wrapping the given logger. The adapter is stored in
+// the stream context and journals messages for the lifetime of the stream.
+// The conditions under which the journal is replayed are the same as those
+// of UnaryServerInterceptor.
+func StreamServerInterceptor(logger gomol.WrappableLogger, configs ...ConfigFunc) grpc.StreamServerInterceptor {
+	i := newInterceptor(logger, configs...)
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		adapter, start := i.start()
+		defer func() { i.finish(adapter, start, err, recover()) }()
+
+		return handler(srv, &wrappedStream{ss, replay.NewContext(ss.Context(), adapter)})
+	}
+}
+
+// WithAdapterConfigs sets the config functions used to create the adapter
+// for each RPC.
+func WithAdapterConfigs(configs ...replay.ConfigFunc) ConfigFunc {
+	return func(i *interceptor) { i.configs = configs }
+}
+
+// WithReplayCodes sets the status codes which cause the journal to be
+// replayed. The default is DefaultReplayCodes.
+func WithReplayCodes(replayCodes ...codes.Code) ConfigFunc {
+	return func(i *interceptor) { i.codes = codeSet(replayCodes) }
+}
+
+// WithLatencyThreshold causes the journal to be replayed when an RPC takes
+// at least the given duration to serve. A value of zero (the default)
+// disables this check.
+func WithLatencyThreshold(threshold time.Duration) ConfigFunc {
+	return func(i *interceptor) { i.latencyThreshold = threshold }
+}
+
+// withNowFunc replaces the function used to read the current time when
+// measuring latency.
+func withNowFunc(now func() time.Time) ConfigFunc {
+	return func(i *interceptor) { i.now = now }
+}
+
+func newInterceptor(logger gomol.WrappableLogger, configs ...ConfigFunc) *interceptor {
+	i := &interceptor{
+		logger: logger,
+		codes:  codeSet(DefaultReplayCodes),
+		now:    time.Now,
+	}
+
+	for _, f := range configs {
+		f(i)
+	}
+
+	return i
+}
+
+func (i *interceptor) start() (*replay.Adapter, time.Time) {
+	return replay.NewConfiguredAdapter(i.logger, i.configs...), i.now()
+}
+
+func (i *interceptor) finish(adapter *replay.Adapter, start time.Time, err error, panicValue interface{}) {
+	if panicValue != nil {
+		adapter.Finish(false)
+		panic(panicValue)
+	}
+
+	adapter.Finish(i.succeeded(err, i.now().Sub(start)))
+}
+
+func (i *interceptor) succeeded(err error, elapsed time.Duration) bool {
+	if _, ok := i.codes[status.Code(err)]; ok && err != nil {
+		return false
+	}
+
+	return i.latencyThreshold <= 0 || elapsed < i.latencyThreshold
+}
+
+func (s *wrappedStream) Context() context.Context {
+	return s.ctx
+}
+
+func codeSet(replayCodes []codes.Code) map[codes.Code]struct{} {
+	set := map[codes.Code]struct{}{}
+	for _, code := range replayCodes {
+		set[code] = struct{}{}
+	}
+
+	return set
+}
@@ -0,0 +1,162 @@
+package grpcreplay
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aphistic/gomol"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	replay "github.com/efritz/gomol-replay"
+
+	. "gopkg.in/check.v1"
+)
+
+type mockServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *mockServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *InterceptorSuite) TestUnarySuccess(c *C) {
+	logger := &mockLogger{}
+	interceptor := UnaryServerInterceptor(logger, WithAdapterConfigs(replay.WithJournaledLevels(gomol.LevelDebug)))
+
+	resp, err := interceptor(context.Background(), "req", &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		adapter, ok := replay.FromContext(ctx)
+		c.Assert(ok, Equals, true)
+		adapter.Debug("foo")
+		return "resp", nil
+	})
+
+	c.Assert(resp, Equals, "resp")
+	c.Assert(err, IsNil)
+	c.Assert(logger.levels, DeepEquals, []gomol.LogLevel{gomol.LevelDebug})
+}
+
+func (s *InterceptorSuite) TestUnaryReplayCodes(c *C) {
+	for _, test := range []struct {
+		err      error
+		replayed bool
+	}{
+		{status.Error(codes.Internal, "oops"), true},
+		{status.Error(codes.Unavailable, "oops"), true},
+		{status.Error(codes.NotFound, "oops"), false},
+		{fmt.Errorf("oops"), true},
+	} {
+		logger := &mockLogger{}
+		interceptor := UnaryServerInterceptor(logger, WithAdapterConfigs(replay.WithJournaledLevels(gomol.LevelDebug)))
+
+		_, err := interceptor(context.Background(), "req", &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			adapter, _ := replay.FromContext(ctx)
+			adapter.Debug("foo")
+			return nil, test.err
+		})
+
+		c.Assert(err, Equals, test.err)
+
+		if test.replayed {
+			c.Assert(logger.levels, DeepEquals, []gomol.LogLevel{gomol.LevelDebug, gomol.LevelError})
+		} else {
+			c.Assert(logger.levels, DeepEquals, []gomol.LogLevel{gomol.LevelDebug})
+		}
+	}
+}
+
+func (s *InterceptorSuite) TestUnaryCustomReplayCodes(c *C) {
+	logger := &mockLogger{}
+	interceptor := UnaryServerInterceptor(
+		logger,
+		WithAdapterConfigs(replay.WithJournaledLevels(gomol.LevelDebug)),
+		WithReplayCodes(codes.NotFound),
+	)
+
+	interceptor(context.Background(), "req", &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		adapter, _ := replay.FromContext(ctx)
+		adapter.Debug("foo")
+		return nil, status.Error(codes.NotFound, "oops")
+	})
+
+	c.Assert(logger.levels, DeepEquals, []gomol.LogLevel{gomol.LevelDebug, gomol.LevelError})
+}
+
+func (s *InterceptorSuite) TestUnaryLatency(c *C) {
+	var (
+		logger      = &mockLogger{}
+		now         = time.Unix(0, 0)
+		interceptor = UnaryServerInterceptor(
+			logger,
+			WithAdapterConfigs(replay.WithJournaledLevels(gomol.LevelDebug)),
+			WithLatencyThreshold(time.Second),
+			withNowFunc(func() time.Time { return now }),
+		)
+	)
+
+	serve := func(elapsed time.Duration) {
+		interceptor(context.Background(), "req", &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			adapter, _ := replay.FromContext(ctx)
+			adapter.Debug("foo")
+			now = now.Add(elapsed)
+			return "resp", nil
+		})
+	}
+
+	serve(time.Second - time.Millisecond)
+	c.Assert(logger.levels, DeepEquals, []gomol.LogLevel{gomol.LevelDebug})
+
+	serve(time.Second)
+	c.Assert(logger.levels, DeepEquals, []gomol.LogLevel{gomol.LevelDebug, gomol.LevelDebug, gomol.LevelError})
+}
+
+func (s *InterceptorSuite) TestUnaryPanic(c *C) {
+	logger := &mockLogger{}
+	interceptor := UnaryServerInterceptor(logger, WithAdapterConfigs(replay.WithJournaledLevels(gomol.LevelDebug)))
+
+	c.Assert(func() {
+		interceptor(context.Background(), "req", &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			adapter, _ := replay.FromContext(ctx)
+			adapter.Debug("foo")
+			panic("oops")
+		})
+	}, PanicMatches, "oops")
+
+	c.Assert(logger.levels, DeepEquals, []gomol.LogLevel{gomol.LevelDebug, gomol.LevelError})
+}
+
+func (s *InterceptorSuite) TestStream(c *C) {
+	var (
+		logger      = &mockLogger{}
+		interceptor = StreamServerInterceptor(logger, WithAdapterConfigs(replay.WithJournaledLevels(gomol.LevelDebug)))
+		stream      = &mockServerStream{ctx: context.Background()}
+		adapters    = []*replay.Adapter{}
+	)
+
+	err := interceptor(nil, stream, &grpc.StreamServerInfo{}, func(srv interface{}, ss grpc.ServerStream) error {
+		for i := 0; i < 3; i++ {
+			adapter, ok := replay.FromContext(ss.Context())
+			c.Assert(ok, Equals, true)
+			adapter.Debug("foo")
+			adapters = append(adapters, adapter)
+		}
+
+		return status.Error(codes.DeadlineExceeded, "oops")
+	})
+
+	c.Assert(err, ErrorMatches, ".*oops")
+	c.Assert(adapters[0], Equals, adapters[1])
+	c.Assert(adapters[0], Equals, adapters[2])
+	c.Assert(logger.levels, DeepEquals, []gomol.LogLevel{
+		gomol.LevelDebug,
+		gomol.LevelDebug,
+		gomol.LevelDebug,
+		gomol.LevelError,
+		gomol.LevelError,
+		gomol.LevelError,
+	})
+}
@@ -0,0 +1,38 @@
+package grpcreplay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aphistic/gomol"
+
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) { TestingT(t) }
+
+type InterceptorSuite struct{}
+
+var _ = Suite(&InterceptorSuite{})
+
+//
+// Mocks
+
+type mockLogger struct {
+	levels []gomol.LogLevel
+}
+
+func (m *mockLogger) LogWithTime(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+	m.levels = append(m.levels, level)
+	return nil
+}
+
+func (m *mockLogger) Log(level gomol.LogLevel, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+	m.levels = append(m.levels, level)
+	return nil
+}
+
+func (m *mockLogger) ShutdownLoggers() error {
+	return nil
+}
@@ -0,0 +1,400 @@
+package gomolreplay
+
+import (
+	"time"
+	"unicode/utf8"
+)
+
+type (
+	// OverflowBehavior determines how a journal with a byte budget makes
+	// room for a new message which does not fit into the remaining budget.
+	OverflowBehavior int
+
+	// EvictionPolicy determines which message is evicted from a full journal.
+	EvictionPolicy int
+
+	journal struct {
+		buffer      []*logMessage
+		head        int
+		size        int
+		maxEntries  int
+		bytes       int64
+		maxBytes    int64
+		maxAge      time.Duration
+		budget      *Budget
+		overflow    OverflowBehavior
+		eviction    EvictionPolicy
+		evicted     int
+		evictedFrom time.Time
+		evictedTo   time.Time
+	}
+)
+
+const (
+	// OverflowDropOldest evicts the oldest messages from the journal until
+	// the new message fits. This is the default behavior.
+	OverflowDropOldest OverflowBehavior = iota
+
+	// OverflowDropNewest discards the new message and keeps the journal
+	// as it is.
+	OverflowDropNewest
+
+	// OverflowTruncate formats a new message which is larger than the entire
+	// budget and truncates the resulting text so that it fits, then evicts
+	// the oldest messages from the journal until it fits.
+	OverflowTruncate
+)
+
+const (
+	// EvictOldest evicts the oldest message in the journal. This is
+	// the default policy.
+	EvictOldest EvictionPolicy = iota
+
+	// EvictLowestLevel evicts the oldest message among those with the
+	// least severe log level in the journal, so that (for example) debug
+	// messages are evicted before info messages. A new message which is
+	// less severe than every message in the journal is dropped instead.
+	EvictLowestLevel
+)
+
+// truncatedSuffix is appended to the text of a truncated message.
+const truncatedSuffix = "... (truncated)"
+
+// add appends the message to the end of the journal. If the journal is
+// at capacity, messages are evicted (or the new message is dropped) as
+// determined by the journal's configuration.
+func (j *journal) add(message *logMessage) {
+	if j.maxEntries > 0 && j.size >= j.maxEntries && j.victim(message) < 0 {
+		j.recordEviction(message)
+		return
+	}
+
+	// Check the byte budget first so that a message is not evicted to make
+	// room for a new message which is then dropped (reserveFor does not
+	// evict anything for a message which cannot fit).
+	if j.hasByteLimit() && !j.reserveFor(message) {
+		j.recordEviction(message)
+		return
+	}
+
+	// If the journal is still full, reserveFor did not evict anything, so
+	// the victim is the same as above and is not the new message.
+	if j.maxEntries > 0 && j.size >= j.maxEntries {
+		j.evict(j.victim(message))
+	}
+
+	if j.size == len(j.buffer) {
+		j.grow()
+	}
+
+	j.buffer[(j.head+j.size)%len(j.buffer)] = message
+	j.size++
+}
+
+// len returns the number of messages currently in the journal.
+func (j *journal) len() int {
+	return j.size
+}
+
+// at returns the ith oldest message in the journal.
+func (j *journal) at(i int) *logMessage {
+	return j.buffer[(j.head+i)%len(j.buffer)]
+}
+
+// messages returns a copy of the journal contents in the order
+// in which they were logged.
+func (j *journal) messages() []*logMessage {
+	messages := make([]*logMessage, 0, j.size)
+	for i := 0; i < j.size; i++ {
+		messages = append(messages, j.at(i))
+	}
+
+	return messages
+}
+
+// expireOldest evicts messages from the front of the journal which were
+// logged before the retention window ending at now. This is cheap, but
+// may miss expired messages logged (with an explicit timestamp) after a
+// message which has not yet expired.
+func (j *journal) expireOldest(now time.Time) {
+	if j.maxAge <= 0 {
+		return
+	}
+
+	cutoff := now.Add(-j.maxAge)
+	for j.size > 0 && j.at(0).ts.Before(cutoff) {
+		j.evict(0)
+	}
+}
+
+// expireAll evicts every message in the journal which was logged before
+// the retention window ending at now.
+func (j *journal) expireAll(now time.Time) {
+	if j.maxAge <= 0 {
+		return
+	}
+
+	cutoff := now.Add(-j.maxAge)
+	j.evictIf(func(message *logMessage) bool { return message.ts.Before(cutoff) })
+}
+
+func (j *journal) hasByteLimit() bool {
+	return j.maxBytes > 0 || j.budget != nil
+}
+
+// reserveFor sizes the message and reserves space for it in the byte
+// budget(s), evicting messages as necessary. Returns false if the
+// message should not be added to the journal.
+func (j *journal) reserveFor(message *logMessage) bool {
+	message.size = estimateSize(message)
+
+	switch j.overflow {
+	case OverflowDropNewest:
+		return j.reserve(message.size)
+
+	case OverflowTruncate:
+		if capacity := j.capacity(); message.size > capacity {
+			if !truncate(message, capacity) {
+				return false
+			}
+		}
+	}
+
+	if j.reserve(message.size) {
+		return true
+	}
+
+	if !j.fits(message) {
+		return false
+	}
+
+	for !j.reserve(message.size) {
+		victim := j.victim(message)
+		if victim < 0 {
+			return false
+		}
+
+		j.evict(victim)
+	}
+
+	return true
+}
+
+// fits determines if the message could be reserved by evicting only the
+// messages which would be chosen as victims before the message itself, so
+// that messages are not evicted to make room for a message which is then
+// dropped anyway. With a shared budget, this is a best-effort check as other
+// adapters may concurrently acquire or release bytes.
+func (j *journal) fits(message *logMessage) bool {
+	evictable := int64(0)
+	for i := 0; i < j.size; i++ {
+		if other := j.at(i); j.eviction != EvictLowestLevel || other.level >= message.level {
+			evictable += other.size
+		}
+	}
+
+	if j.maxBytes > 0 && message.size > j.maxBytes-j.bytes+evictable {
+		return false
+	}
+
+	if j.budget != nil && message.size > j.budget.maxBytes-j.budget.Used()+evictable {
+		return false
+	}
+
+	return true
+}
+
+// capacity returns the largest message size which could fit into
+// an empty journal.
+func (j *journal) capacity() int64 {
+	capacity := j.maxBytes
+	if j.budget != nil && (capacity == 0 || j.budget.maxBytes < capacity) {
+		capacity = j.budget.maxBytes
+	}
+
+	return capacity
+}
+
+func (j *journal) reserve(n int64) bool {
+	if j.maxBytes > 0 && j.bytes+n > j.maxBytes {
+		return false
+	}
+
+	if j.budget != nil && !j.budget.acquire(n) {
+		return false
+	}
+
+	j.bytes += n
+	return true
+}
+
+func (j *journal) release(n int64) {
+	j.bytes -= n
+
+	if j.budget != nil {
+		j.budget.release(n)
+	}
+}
+
+// victim returns the index of the message which should be evicted next
+// according to the journal's eviction policy to make room for the given
+// incoming message, or -1 if the incoming message should be dropped instead.
+func (j *journal) victim(incoming *logMessage) int {
+	if j.size == 0 {
+		return -1
+	}
+
+	if j.eviction != EvictLowestLevel {
+		return 0
+	}
+
+	// A larger level value is less severe
+	victim := 0
+	for i := 1; i < j.size; i++ {
+		if j.at(i).level > j.at(victim).level {
+			victim = i
+		}
+	}
+
+	// Drop the incoming message only if it is strictly less severe than
+	// every journaled message. On a tie, the oldest message at that level
+	// is evicted, so the incoming message survives.
+	if incoming.level > j.at(victim).level {
+		return -1
+	}
+
+	return victim
+}
+
+// evict removes the ith oldest message from the journal and
+// updates the eviction bookkeeping.
+func (j *journal) evict(i int) {
+	message := j.at(i)
+	j.recordEviction(message)
+	j.release(message.size)
+	j.remove(i)
+}
+
+// evictIf evicts every message for which f returns true while
+// preserving the order of the remaining messages.
+func (j *journal) evictIf(f func(*logMessage) bool) {
+	kept := 0
+	for i := 0; i < j.size; i++ {
+		message := j.at(i)
+
+		if f(message) {
+			j.recordEviction(message)
+			j.release(message.size)
+			continue
+		}
+
+		j.buffer[(j.head+kept)%len(j.buffer)] = message
+		kept++
+	}
+
+	for i := kept; i < j.size; i++ {
+		j.buffer[(j.head+i)%len(j.buffer)] = nil
+	}
+
+	j.size = kept
+}
+
+func (j *journal) recordEviction(message *logMessage) {
+	if j.evicted == 0 || message.ts.Before(j.evictedFrom) {
+		j.evictedFrom = message.ts
+	}
+
+	if j.evicted == 0 || message.ts.After(j.evictedTo) {
+		j.evictedTo = message.ts
+	}
+
+	j.evicted++
+}
+
+func (j *journal) remove(i int) {
+	if i == 0 {
+		j.buffer[j.head] = nil
+		j.head = (j.head + 1) % len(j.buffer)
+		j.size--
+		return
+	}
+
+	for ; i < j.size-1; i++ {
+		j.buffer[(j.head+i)%len(j.buffer)] = j.at(i + 1)
+	}
+
+	j.buffer[(j.head+j.size-1)%len(j.buffer)] = nil
+	j.size--
+}
+
+func (j *journal) grow() {
+	capacity := len(j.buffer) * 2
+	if capacity == 0 {
+		capacity = 16
+	}
+
+	if j.maxEntries > 0 && capacity > j.maxEntries {
+		capacity = j.maxEntries
+	}
+
+	buffer := make([]*logMessage, capacity)
+	for i := 0; i < j.size; i++ {
+		buffer[i] = j.at(i)
+	}
+
+	j.buffer = buffer
+	j.head = 0
+}
+
+// reset removes all messages from the journal, returns their bytes to
+// the budget, and clears the eviction bookkeeping. The underlying buffer
+// is retained for reuse.
+func (j *journal) reset() {
+	for i := range j.buffer {
+		j.buffer[i] = nil
+	}
+
+	if j.budget != nil {
+		j.budget.release(j.bytes)
+	}
+
+	j.head = 0
+	j.size = 0
+	j.bytes = 0
+	j.evicted = 0
+	j.evictedFrom = time.Time{}
+	j.evictedTo = time.Time{}
+}
+
+// truncate replaces the message's format string and arguments with its
+// formatted text, cut short so that the size of the message does not
+// exceed capacity. Returns false if the message cannot be made to fit.
+func truncate(message *logMessage, capacity int64) bool {
+	if message.original == "" {
+		message.original = message.msg
+		message.args = []interface{}{formatMessage(message.msg, message.args)}
+		message.msg = "%s"
+	}
+
+	text := message.args[0].(string)
+	message.size = estimateSize(message)
+
+	excess := message.size - capacity
+	if excess <= 0 {
+		return true
+	}
+
+	keep := int64(len(text)) - excess - int64(len(truncatedSuffix))
+	if keep < 0 {
+		return false
+	}
+
+	// Do not split a multi-byte character
+	for keep > 0 && !utf8.RuneStart(text[keep]) {
+		keep--
+	}
+
+	message.args[0] = text[:keep] + truncatedSuffix
+	message.size = estimateSize(message)
+	return true
+}
@@ -0,0 +1,272 @@
+package gomolreplay
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aphistic/gomol"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *ReplaySuite) TestJournalGrows(c *C) {
+	j := &journal{}
+
+	for i := 0; i < 100; i++ {
+		j.add(&logMessage{msg: string(rune('a' + i%26))})
+	}
+
+	c.Assert(j.len(), Equals, 100)
+	c.Assert(j.evicted, Equals, 0)
+
+	for i, message := range j.messages() {
+		c.Assert(message.msg, Equals, string(rune('a'+i%26)))
+	}
+}
+
+func (s *ReplaySuite) TestJournalEvictsOldest(c *C) {
+	j := &journal{maxEntries: 3}
+
+	for i := 0; i < 7; i++ {
+		j.add(&logMessage{msg: string(rune('a' + i)), ts: time.Unix(int64(i), 0)})
+	}
+
+	c.Assert(j.len(), Equals, 3)
+	c.Assert(len(j.buffer), Equals, 3)
+	c.Assert(j.evicted, Equals, 4)
+	c.Assert(j.evictedFrom, Equals, time.Unix(0, 0))
+	c.Assert(j.evictedTo, Equals, time.Unix(3, 0))
+
+	messages := j.messages()
+	c.Assert(messages[0].msg, Equals, "e")
+	c.Assert(messages[1].msg, Equals, "f")
+	c.Assert(messages[2].msg, Equals, "g")
+}
+
+func (s *ReplaySuite) TestJournalRemoveWraps(c *C) {
+	j := &journal{maxEntries: 4}
+
+	for i := 0; i < 6; i++ {
+		j.add(&logMessage{msg: string(rune('a' + i))})
+	}
+
+	j.remove(1)
+	j.add(&logMessage{msg: "g"})
+
+	messages := j.messages()
+	c.Assert(len(messages), Equals, 4)
+	c.Assert(messages[0].msg, Equals, "c")
+	c.Assert(messages[1].msg, Equals, "e")
+	c.Assert(messages[2].msg, Equals, "f")
+	c.Assert(messages[3].msg, Equals, "g")
+}
+
+func (s *ReplaySuite) TestJournalReset(c *C) {
+	j := &journal{maxEntries: 2}
+
+	for i := 0; i < 4; i++ {
+		j.add(&logMessage{msg: "a"})
+	}
+
+	j.reset()
+	c.Assert(j.len(), Equals, 0)
+	c.Assert(j.evicted, Equals, 0)
+	c.Assert(len(j.buffer), Equals, 2)
+
+	for _, message := range j.buffer {
+		c.Assert(message, IsNil)
+	}
+}
+
+func (s *ReplaySuite) TestJournalByteLimitDropOldest(c *C) {
+	j := &journal{maxBytes: 3 * (messageOverhead + 3)}
+
+	for _, msg := range []string{"foo", "bar", "baz", "bnk"} {
+		j.add(&logMessage{msg: msg})
+	}
+
+	messages := j.messages()
+	c.Assert(len(messages), Equals, 3)
+	c.Assert(messages[0].msg, Equals, "bar")
+	c.Assert(messages[2].msg, Equals, "bnk")
+	c.Assert(j.bytes, Equals, int64(3*(messageOverhead+3)))
+	c.Assert(j.evicted, Equals, 1)
+
+	// A message which can never fit is dropped without evicting anything
+	j.add(&logMessage{msg: strings.Repeat("x", 1000)})
+	c.Assert(j.len(), Equals, 3)
+	c.Assert(j.bytes, Equals, int64(3*(messageOverhead+3)))
+	c.Assert(j.evicted, Equals, 2)
+}
+
+func (s *ReplaySuite) TestJournalByteLimitDropNewest(c *C) {
+	j := &journal{maxBytes: 2 * (messageOverhead + 3), overflow: OverflowDropNewest}
+
+	for _, msg := range []string{"foo", "bar", "baz", "bnk"} {
+		j.add(&logMessage{msg: msg})
+	}
+
+	messages := j.messages()
+	c.Assert(len(messages), Equals, 2)
+	c.Assert(messages[0].msg, Equals, "foo")
+	c.Assert(messages[1].msg, Equals, "bar")
+	c.Assert(j.evicted, Equals, 2)
+}
+
+func (s *ReplaySuite) TestJournalMaxEntriesDropNewest(c *C) {
+	j := &journal{maxEntries: 2, maxBytes: 2 * (messageOverhead + 3), overflow: OverflowDropNewest}
+
+	for _, msg := range []string{"foo", "bar", "bazbnk"} {
+		j.add(&logMessage{msg: msg})
+	}
+
+	// The dropped message does not cause the oldest message to be evicted
+	messages := j.messages()
+	c.Assert(len(messages), Equals, 2)
+	c.Assert(messages[0].msg, Equals, "foo")
+	c.Assert(messages[1].msg, Equals, "bar")
+	c.Assert(j.evicted, Equals, 1)
+}
+
+func (s *ReplaySuite) TestJournalByteLimitTruncate(c *C) {
+	j := &journal{maxBytes: messageOverhead + 100, overflow: OverflowTruncate}
+
+	j.add(&logMessage{msg: "foo"})
+	j.add(&logMessage{msg: "body=%s", args: []interface{}{strings.Repeat("x", 1000)}})
+
+	messages := j.messages()
+	c.Assert(len(messages), Equals, 1)
+	c.Assert(messages[0].msg, Equals, "%s")
+	c.Assert(messages[0].args[0], HasLen, 98)
+	c.Assert(strings.HasPrefix(messages[0].args[0].(string), "body=xxx"), Equals, true)
+	c.Assert(strings.HasSuffix(messages[0].args[0].(string), truncatedSuffix), Equals, true)
+	c.Assert(messages[0].size, Equals, int64(messageOverhead+100))
+	c.Assert(j.bytes, Equals, int64(messageOverhead+100))
+	c.Assert(j.evicted, Equals, 1)
+}
+
+func (s *ReplaySuite) TestJournalByteLimitTruncateTooSmall(c *C) {
+	j := &journal{maxBytes: messageOverhead + 10, overflow: OverflowTruncate}
+
+	j.add(&logMessage{msg: strings.Repeat("x", 1000)})
+	c.Assert(j.len(), Equals, 0)
+	c.Assert(j.evicted, Equals, 1)
+}
+
+func (s *ReplaySuite) TestJournalSharedBudget(c *C) {
+	var (
+		budget = NewBudget(4 * (messageOverhead + 3))
+		j1     = &journal{budget: budget}
+		j2     = &journal{budget: budget}
+	)
+
+	j1.add(&logMessage{msg: "foo"})
+	j1.add(&logMessage{msg: "bar"})
+	j1.add(&logMessage{msg: "baz"})
+	j2.add(&logMessage{msg: "bnk"})
+	c.Assert(budget.Used(), Equals, int64(4*(messageOverhead+3)))
+
+	// j2 can only make room by evicting its own messages
+	j2.add(&logMessage{msg: "qux"})
+	c.Assert(j1.len(), Equals, 3)
+	c.Assert(j2.len(), Equals, 1)
+	c.Assert(j2.at(0).msg, Equals, "qux")
+
+	// j2 cannot make room for a message larger than its own messages plus
+	// the unused budget, so it keeps its messages
+	j2.add(&logMessage{msg: strings.Repeat("x", 10)})
+	c.Assert(j2.len(), Equals, 1)
+	c.Assert(j2.at(0).msg, Equals, "qux")
+	c.Assert(budget.Used(), Equals, int64(4*(messageOverhead+3)))
+
+	j1.reset()
+	c.Assert(budget.Used(), Equals, int64(messageOverhead+3))
+}
+
+func (s *ReplaySuite) TestJournalExpire(c *C) {
+	j := &journal{maxAge: 10 * time.Second}
+
+	j.add(&logMessage{msg: "foo", ts: time.Unix(10, 0)})
+	j.add(&logMessage{msg: "bar", ts: time.Unix(15, 0)})
+	j.add(&logMessage{msg: "baz", ts: time.Unix(12, 0)})
+	j.add(&logMessage{msg: "bnk", ts: time.Unix(20, 0)})
+
+	j.expireOldest(time.Unix(23, 0))
+	c.Assert(j.len(), Equals, 3)
+	c.Assert(j.at(0).msg, Equals, "bar")
+
+	j.expireAll(time.Unix(23, 0))
+	c.Assert(j.len(), Equals, 2)
+	c.Assert(j.at(0).msg, Equals, "bar")
+	c.Assert(j.at(1).msg, Equals, "bnk")
+	c.Assert(j.evicted, Equals, 2)
+	c.Assert(j.evictedFrom, Equals, time.Unix(10, 0))
+	c.Assert(j.evictedTo, Equals, time.Unix(12, 0))
+}
+
+func (s *ReplaySuite) TestJournalEvictLowestLevel(c *C) {
+	j := &journal{maxEntries: 4, eviction: EvictLowestLevel}
+
+	j.add(&logMessage{msg: "foo", level: gomol.LevelInfo})
+	j.add(&logMessage{msg: "bar", level: gomol.LevelDebug})
+	j.add(&logMessage{msg: "baz", level: gomol.LevelInfo})
+	j.add(&logMessage{msg: "bnk", level: gomol.LevelDebug})
+	j.add(&logMessage{msg: "qux", level: gomol.LevelInfo})
+	j.add(&logMessage{msg: "xyz", level: gomol.LevelInfo})
+
+	messages := j.messages()
+	c.Assert(len(messages), Equals, 4)
+	c.Assert(messages[0].msg, Equals, "foo")
+	c.Assert(messages[1].msg, Equals, "baz")
+	c.Assert(messages[2].msg, Equals, "qux")
+	c.Assert(messages[3].msg, Equals, "xyz")
+
+	j.add(&logMessage{msg: "abc", level: gomol.LevelWarning})
+	j.add(&logMessage{msg: "def", level: gomol.LevelDebug})
+
+	// The new debug message is less severe than every journaled message
+	messages = j.messages()
+	c.Assert(len(messages), Equals, 4)
+	c.Assert(messages[0].msg, Equals, "baz")
+	c.Assert(messages[1].msg, Equals, "qux")
+	c.Assert(messages[2].msg, Equals, "xyz")
+	c.Assert(messages[3].msg, Equals, "abc")
+	c.Assert(j.evicted, Equals, 4)
+}
+
+func (s *ReplaySuite) TestJournalEvictLowestLevelByteLimit(c *C) {
+	j := &journal{maxBytes: 2 * (messageOverhead + 3), eviction: EvictLowestLevel}
+
+	j.add(&logMessage{msg: "foo", level: gomol.LevelInfo})
+	j.add(&logMessage{msg: "bar", level: gomol.LevelDebug})
+	j.add(&logMessage{msg: "baz", level: gomol.LevelInfo})
+
+	messages := j.messages()
+	c.Assert(len(messages), Equals, 2)
+	c.Assert(messages[0].msg, Equals, "foo")
+	c.Assert(messages[1].msg, Equals, "baz")
+
+	j.add(&logMessage{msg: "bnk", level: gomol.LevelDebug})
+
+	messages = j.messages()
+	c.Assert(len(messages), Equals, 2)
+	c.Assert(messages[0].msg, Equals, "foo")
+	c.Assert(messages[1].msg, Equals, "baz")
+	c.Assert(j.evicted, Equals, 2)
+}
+
+func (s *ReplaySuite) TestJournalEvictLowestLevelByteLimitTooLarge(c *C) {
+	j := &journal{maxBytes: 3 * (messageOverhead + 3), eviction: EvictLowestLevel}
+
+	j.add(&logMessage{msg: "foo", level: gomol.LevelInfo})
+	j.add(&logMessage{msg: "bar", level: gomol.LevelDebug})
+	j.add(&logMessage{msg: "baz", level: gomol.LevelInfo})
+
+	// Making room would require evicting more severe messages
+	j.add(&logMessage{msg: "bnk" + strings.Repeat("x", messageOverhead), level: gomol.LevelDebug})
+
+	messages := j.messages()
+	c.Assert(len(messages), Equals, 3)
+	c.Assert(messages[1].msg, Equals, "bar")
+	c.Assert(j.evicted, Equals, 1)
+}
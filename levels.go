@@ -0,0 +1,39 @@
+package gomolreplay
+
+import "github.com/aphistic/gomol"
+
+// maxLevelBits is the number of distinct log levels which can be
+// represented in a level set.
+const maxLevelBits = 64
+
+// levelSet returns a bitmask in which the bit for each of the given
+// levels is set. Levels outside of the range [0, 64) are ignored.
+func levelSet(levels ...gomol.LogLevel) uint64 {
+	set := uint64(0)
+	for _, level := range levels {
+		if level >= 0 && level < maxLevelBits {
+			set |= 1 << uint(level)
+		}
+	}
+
+	return set
+}
+
+// levelsBelow returns a bitmask in which the bit for every level less
+// severe than the given level (i.e. with a larger value) is set.
+func levelsBelow(level gomol.LogLevel) uint64 {
+	if level < 0 {
+		return ^uint64(0)
+	}
+
+	if level >= maxLevelBits-1 {
+		return 0
+	}
+
+	return ^uint64(0) << uint(level+1)
+}
+
+// containsLevel determines if the bit for the given level is set.
+func containsLevel(set uint64, level gomol.LogLevel) bool {
+	return level >= 0 && level < maxLevelBits && set&(1<<uint(level)) != 0
+}
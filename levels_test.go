@@ -0,0 +1,105 @@
+package gomolreplay
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/aphistic/gomol"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *ReplaySuite) TestLevelSet(c *C) {
+	set := levelSet(gomol.LevelDebug, gomol.LevelError, gomol.LogLevel(-1), gomol.LogLevel(64))
+
+	c.Assert(containsLevel(set, gomol.LevelDebug), Equals, true)
+	c.Assert(containsLevel(set, gomol.LevelInfo), Equals, false)
+	c.Assert(containsLevel(set, gomol.LevelWarning), Equals, false)
+	c.Assert(containsLevel(set, gomol.LevelError), Equals, true)
+	c.Assert(containsLevel(set, gomol.LogLevel(-1)), Equals, false)
+	c.Assert(containsLevel(set, gomol.LogLevel(64)), Equals, false)
+}
+
+func (s *ReplaySuite) TestLevelsBelow(c *C) {
+	set := levelsBelow(gomol.LevelWarning)
+
+	c.Assert(containsLevel(set, gomol.LevelDebug), Equals, true)
+	c.Assert(containsLevel(set, gomol.LevelInfo), Equals, true)
+	c.Assert(containsLevel(set, gomol.LevelWarning), Equals, false)
+	c.Assert(containsLevel(set, gomol.LevelError), Equals, false)
+	c.Assert(containsLevel(set, gomol.LevelFatal), Equals, false)
+
+	c.Assert(levelsBelow(gomol.LogLevel(-1)), Equals, ^uint64(0))
+	c.Assert(levelsBelow(gomol.LogLevel(63)), Equals, uint64(0))
+}
+
+func (s *ReplaySuite) TestJournalThreshold(c *C) {
+	adapter := NewConfiguredAdapter(newDefaultMockLogger(), WithJournalThreshold(gomol.LevelWarning))
+
+	adapter.Debug("foo")
+	adapter.Info("bar")
+	adapter.Warning("baz")
+	adapter.Error("bnk")
+
+	c.Assert(adapter.journal.len(), Equals, 2)
+	c.Assert(adapter.journal.at(0).msg, Equals, "foo")
+	c.Assert(adapter.journal.at(1).msg, Equals, "bar")
+}
+
+func (s *ReplaySuite) TestSetJournaledLevels(c *C) {
+	adapter := NewAdapter(newDefaultMockLogger(), gomol.LevelDebug)
+
+	adapter.Debug("foo")
+	adapter.Info("bar")
+
+	adapter.SetJournaledLevels(gomol.LevelInfo)
+	adapter.Debug("baz")
+	adapter.Info("bnk")
+
+	adapter.SetJournalThreshold(gomol.LevelInfo)
+	adapter.Debug("qux")
+	adapter.Info("quux")
+
+	c.Assert(adapter.journal.len(), Equals, 3)
+	c.Assert(adapter.journal.at(0).msg, Equals, "foo")
+	c.Assert(adapter.journal.at(1).msg, Equals, "bnk")
+	c.Assert(adapter.journal.at(2).msg, Equals, "qux")
+}
+
+func (s *ReplaySuite) TestSetJournaledLevelsConcurrently(c *C) {
+	var (
+		adapter = NewAdapter(newDefaultMockLogger(), gomol.LevelDebug)
+		wg      sync.WaitGroup
+	)
+
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				adapter.Debug("foo")
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				adapter.SetJournalThreshold(gomol.LevelInfo)
+				adapter.SetJournaledLevels()
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func BenchmarkShouldJournal(b *testing.B) {
+	adapter := NewAdapter(newDefaultMockLogger(), gomol.LevelDebug, gomol.LevelInfo)
+
+	for i := 0; i < b.N; i++ {
+		adapter.shouldJournal(gomol.LevelWarning)
+	}
+}
@@ -1,6 +1,7 @@
 package gomolreplay
 
 import (
+	"sync"
 	"testing"
 	"time"
 
@@ -58,6 +59,14 @@ func (m *mockLogger) ShutdownLoggers() error {
 type mockClock struct {
 	seconds     int64
 	nanoseconds int64
+	timers      []*mockTimer
+	mutex       sync.Mutex
+}
+
+type mockTimer struct {
+	clock    *mockClock
+	deadline time.Time
+	ch       chan time.Time
 }
 
 func newMockClock(ms int64) *mockClock {
@@ -67,6 +76,9 @@ func newMockClock(ms int64) *mockClock {
 }
 
 func (m *mockClock) advance(ms int64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
 	m.seconds += ms / 1000
 	m.nanoseconds += (ms % 1000) * 1e6
 
@@ -74,8 +86,56 @@ func (m *mockClock) advance(ms int64) {
 		m.seconds++
 		m.nanoseconds -= 1e9
 	}
+
+	now := m.now()
+	timers := m.timers[:0]
+
+	for _, t := range m.timers {
+		if t.deadline.After(now) {
+			timers = append(timers, t)
+			continue
+		}
+
+		t.ch <- now
+	}
+
+	m.timers = timers
 }
 
 func (m *mockClock) Now() time.Time {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.now()
+}
+
+func (m *mockClock) now() time.Time {
 	return time.Unix(m.seconds, m.nanoseconds)
 }
+
+func (m *mockClock) NewTimer(d time.Duration) timer {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	t := &mockTimer{clock: m, deadline: m.now().Add(d), ch: make(chan time.Time, 1)}
+	m.timers = append(m.timers, t)
+	return t
+}
+
+func (t *mockTimer) Chan() <-chan time.Time {
+	return t.ch
+}
+
+func (t *mockTimer) Stop() bool {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+
+	for i, other := range t.clock.timers {
+		if other == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,67 @@
+package gomolreplay
+
+import "github.com/aphistic/gomol"
+
+// LevelMapping determines the level at which a replayed message is sent
+// from the level at which it was originally logged.
+type LevelMapping func(gomol.LogLevel) gomol.LogLevel
+
+// orderedLevels lists the gomol log levels from least to most severe.
+var orderedLevels = []gomol.LogLevel{
+	gomol.LevelDebug,
+	gomol.LevelInfo,
+	gomol.LevelWarning,
+	gomol.LevelError,
+	gomol.LevelFatal,
+}
+
+// MapLevels creates a LevelMapping from the given map. Messages logged at a
+// level which is not in the map are replayed at their original level.
+func MapLevels(levels map[gomol.LogLevel]gomol.LogLevel) LevelMapping {
+	return func(level gomol.LogLevel) gomol.LogLevel {
+		if mapped, ok := levels[level]; ok {
+			return mapped
+		}
+
+		return level
+	}
+}
+
+// RaiseLevels creates a LevelMapping which raises the severity of each
+// message by the given number of steps in the order debug, info, warning,
+// error, fatal. For example, with one step debug messages are replayed at
+// info and info messages are replayed at warning. Levels are never raised
+// above fatal.
+func RaiseLevels(steps int) LevelMapping {
+	return func(level gomol.LogLevel) gomol.LogLevel {
+		for i, l := range orderedLevels {
+			if l != level {
+				continue
+			}
+
+			i += steps
+			if i >= len(orderedLevels) {
+				i = len(orderedLevels) - 1
+			}
+
+			if i < 0 {
+				i = 0
+			}
+
+			return orderedLevels[i]
+		}
+
+		return level
+	}
+}
+
+// ReplayMapped is similar to Replay, but each message is replayed at the
+// level determined by the given mapping from its original level. This also
+// applies to future messages logged at one of the journaled levels. Unlike
+// Replay, this method always replays the journal, and a subsequent call to
+// Replay always replays the journal.
+func (a *Adapter) ReplayMapped(mapping LevelMapping) error {
+	replaying := a.newReplay(mapping(gomol.LevelDebug), "")
+	replaying.mapping = mapping
+	return a.replay(replaying)
+}
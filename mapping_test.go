@@ -0,0 +1,91 @@
+package gomolreplay
+
+import (
+	"time"
+
+	"github.com/aphistic/gomol"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *ReplaySuite) TestMapLevels(c *C) {
+	mapping := MapLevels(map[gomol.LogLevel]gomol.LogLevel{
+		gomol.LevelDebug: gomol.LevelInfo,
+		gomol.LevelInfo:  gomol.LevelWarning,
+	})
+
+	c.Assert(mapping(gomol.LevelDebug), Equals, gomol.LevelInfo)
+	c.Assert(mapping(gomol.LevelInfo), Equals, gomol.LevelWarning)
+	c.Assert(mapping(gomol.LevelError), Equals, gomol.LevelError)
+}
+
+func (s *ReplaySuite) TestRaiseLevels(c *C) {
+	mapping := RaiseLevels(2)
+	c.Assert(mapping(gomol.LevelDebug), Equals, gomol.LevelWarning)
+	c.Assert(mapping(gomol.LevelInfo), Equals, gomol.LevelError)
+	c.Assert(mapping(gomol.LevelError), Equals, gomol.LevelFatal)
+	c.Assert(mapping(gomol.LevelFatal), Equals, gomol.LevelFatal)
+	c.Assert(mapping(gomol.LogLevel(42)), Equals, gomol.LogLevel(42))
+
+	mapping = RaiseLevels(-1)
+	c.Assert(mapping(gomol.LevelDebug), Equals, gomol.LevelDebug)
+	c.Assert(mapping(gomol.LevelError), Equals, gomol.LevelWarning)
+}
+
+func (s *ReplaySuite) TestReplayMapped(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		adapter  = NewAdapter(logger, gomol.LevelDebug, gomol.LevelInfo)
+		messages = []logArgs{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Info("bar")
+	adapter.ReplayMapped(RaiseLevels(1))
+	adapter.Info("baz")
+
+	c.Assert(len(messages), Equals, 6)
+	c.Assert(messages[2].level, Equals, gomol.LevelInfo)
+	c.Assert(messages[3].level, Equals, gomol.LevelWarning)
+	c.Assert(messages[4].level, Equals, gomol.LevelInfo)
+	c.Assert(messages[5].level, Equals, gomol.LevelWarning)
+	c.Assert(messages[5].attrs.GetAttr(AttrReplay), Equals, gomol.LevelInfo)
+
+	// Replay after a mapped replay always replays
+	adapter.Replay(gomol.LevelDebug)
+	c.Assert(len(messages), Equals, 9)
+
+	for i := 6; i < 9; i++ {
+		c.Assert(messages[i].level, Equals, gomol.LevelDebug)
+	}
+}
+
+func (s *ReplaySuite) TestReplayMappedEvictionNotice(c *C) {
+	var (
+		logger  = newDefaultMockLogger()
+		adapter = NewConfiguredAdapter(logger, WithJournaledLevels(gomol.LevelDebug, gomol.LevelInfo), WithMaxEntries(1))
+		levels  = []gomol.LogLevel{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		levels = append(levels, level)
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Info("bar")
+	adapter.ReplayMapped(MapLevels(map[gomol.LogLevel]gomol.LogLevel{gomol.LevelInfo: gomol.LevelError}))
+
+	// Eviction notice is sent at the most severe mapped level
+	c.Assert(levels, DeepEquals, []gomol.LogLevel{
+		gomol.LevelDebug,
+		gomol.LevelInfo,
+		gomol.LevelError,
+		gomol.LevelError,
+	})
+}
@@ -0,0 +1,137 @@
+package gomolreplay
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aphistic/gomol"
+)
+
+type (
+	// MiddlewareConfigFunc is a function used to configure HTTP middleware.
+	MiddlewareConfigFunc func(*middleware)
+
+	middleware struct {
+		logger           gomol.WrappableLogger
+		configs          []ConfigFunc
+		latencyThreshold time.Duration
+		clock            clock
+	}
+
+	statusRecorder struct {
+		http.ResponseWriter
+		status      int
+		wroteHeader bool
+	}
+)
+
+// NewMiddleware returns a function which wraps an http.Handler so that each
+// request is served with a new Adapter which wraps the given logger. The
+// adapter is stored in the request context and can be retrieved by the
+// handler with FromContext. The journal is replayed (as if by calling Finish
+// with an unsuccessful result) if the handler responds with a 5xx status,
+// panics, or exceeds the configured latency threshold. Otherwise, the
+// journal is discarded.
+func NewMiddleware(logger gomol.WrappableLogger, configs ...MiddlewareConfigFunc) func(http.Handler) http.Handler {
+	m := &middleware{
+		logger: logger,
+		clock:  &realClock{},
+	}
+
+	for _, f := range configs {
+		f(m)
+	}
+
+	return m.wrap
+}
+
+// WithAdapterConfigs sets the config functions used to create the adapter
+// for each request.
+func WithAdapterConfigs(configs ...ConfigFunc) MiddlewareConfigFunc {
+	return func(m *middleware) { m.configs = configs }
+}
+
+// WithLatencyThreshold causes the journal to be replayed when a request
+// takes at least the given duration to serve. A value of zero (the default)
+// disables this check.
+func WithLatencyThreshold(threshold time.Duration) MiddlewareConfigFunc {
+	return func(m *middleware) { m.latencyThreshold = threshold }
+}
+
+func withMiddlewareClock(clock clock) MiddlewareConfigFunc {
+	return func(m *middleware) { m.clock = clock }
+}
+
+func (m *middleware) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			adapter  = NewConfiguredAdapter(m.logger, m.configs...)
+			recorder = &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			start    = m.clock.Now()
+		)
+
+		defer func() {
+			if value := recover(); value != nil {
+				adapter.Finish(false)
+				panic(value)
+			}
+
+			adapter.Finish(m.succeeded(recorder.status, m.clock.Now().Sub(start)))
+		}()
+
+		next.ServeHTTP(recorder.writer(), r.WithContext(NewContext(r.Context(), adapter)))
+	})
+}
+
+func (m *middleware) succeeded(status int, elapsed time.Duration) bool {
+	if status >= 500 {
+		return false
+	}
+
+	return m.latencyThreshold <= 0 || elapsed < m.latencyThreshold
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(data []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(data)
+}
+
+// writer returns the recorder as a response writer which implements
+// http.Flusher and http.Hijacker only if the underlying writer does, so
+// that handlers which check for these interfaces are not misled.
+func (r *statusRecorder) writer() http.ResponseWriter {
+	flusher, canFlush := r.ResponseWriter.(http.Flusher)
+	hijacker, canHijack := r.ResponseWriter.(http.Hijacker)
+
+	switch {
+	case canFlush && canHijack:
+		return struct {
+			*statusRecorder
+			http.Flusher
+			http.Hijacker
+		}{r, flusher, hijacker}
+
+	case canFlush:
+		return struct {
+			*statusRecorder
+			http.Flusher
+		}{r, flusher}
+
+	case canHijack:
+		return struct {
+			*statusRecorder
+			http.Hijacker
+		}{r, hijacker}
+	}
+
+	return r
+}
@@ -0,0 +1,147 @@
+package gomolreplay
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/aphistic/gomol"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *ReplaySuite) TestMiddlewareSuccess(c *C) {
+	logger, messages := newMiddlewareTestLogger()
+
+	handler := NewMiddleware(
+		logger,
+		WithAdapterConfigs(WithJournaledLevels(gomol.LevelDebug)),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adapter, ok := FromContext(r.Context())
+		c.Assert(ok, Equals, true)
+		adapter.Debug("foo")
+		w.Write([]byte("ok"))
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+
+	c.Assert(recorder.Code, Equals, http.StatusOK)
+	c.Assert(*messages, DeepEquals, []gomol.LogLevel{gomol.LevelDebug})
+}
+
+func (s *ReplaySuite) TestMiddlewareOptionalInterfaces(c *C) {
+	var canFlush, canHijack bool
+
+	handler := NewMiddleware(newDefaultMockLogger())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, canFlush = w.(http.Flusher)
+		_, canHijack = w.(http.Hijacker)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+	c.Assert(canFlush, Equals, true)
+	c.Assert(canHijack, Equals, false)
+	c.Assert(recorder.Code, Equals, http.StatusInternalServerError)
+
+	handler.ServeHTTP(struct{ http.ResponseWriter }{httptest.NewRecorder()}, httptest.NewRequest("GET", "/", nil))
+	c.Assert(canFlush, Equals, false)
+	c.Assert(canHijack, Equals, false)
+}
+
+func (s *ReplaySuite) TestMiddlewareServerError(c *C) {
+	logger, messages := newMiddlewareTestLogger()
+
+	handler := NewMiddleware(
+		logger,
+		WithAdapterConfigs(WithJournaledLevels(gomol.LevelDebug)),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adapter, _ := FromContext(r.Context())
+		adapter.Debug("foo")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+
+	c.Assert(recorder.Code, Equals, http.StatusServiceUnavailable)
+	c.Assert(*messages, DeepEquals, []gomol.LogLevel{gomol.LevelDebug, gomol.LevelError})
+}
+
+func (s *ReplaySuite) TestMiddlewareClientErrorDoesNotReplay(c *C) {
+	logger, messages := newMiddlewareTestLogger()
+
+	handler := NewMiddleware(
+		logger,
+		WithAdapterConfigs(WithJournaledLevels(gomol.LevelDebug)),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adapter, _ := FromContext(r.Context())
+		adapter.Debug("foo")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	c.Assert(*messages, DeepEquals, []gomol.LogLevel{gomol.LevelDebug})
+}
+
+func (s *ReplaySuite) TestMiddlewarePanic(c *C) {
+	logger, messages := newMiddlewareTestLogger()
+
+	handler := NewMiddleware(
+		logger,
+		WithAdapterConfigs(WithJournaledLevels(gomol.LevelDebug), WithFinishLevel(gomol.LevelFatal)),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adapter, _ := FromContext(r.Context())
+		adapter.Debug("foo")
+		panic("oops")
+	}))
+
+	c.Assert(func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}, PanicMatches, "oops")
+
+	c.Assert(*messages, DeepEquals, []gomol.LogLevel{gomol.LevelDebug, gomol.LevelFatal})
+}
+
+func (s *ReplaySuite) TestMiddlewareLatency(c *C) {
+	var (
+		logger, messages = newMiddlewareTestLogger()
+		clock            = newMockClock(0)
+		elapsed          = int64(0)
+	)
+
+	handler := NewMiddleware(
+		logger,
+		WithAdapterConfigs(WithJournaledLevels(gomol.LevelDebug)),
+		WithLatencyThreshold(time.Second),
+		withMiddlewareClock(clock),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adapter, _ := FromContext(r.Context())
+		adapter.Debug("foo")
+		clock.advance(elapsed)
+	}))
+
+	elapsed = 999
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	c.Assert(*messages, DeepEquals, []gomol.LogLevel{gomol.LevelDebug})
+
+	elapsed = 1000
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	c.Assert(*messages, DeepEquals, []gomol.LogLevel{gomol.LevelDebug, gomol.LevelDebug, gomol.LevelError})
+}
+
+func newMiddlewareTestLogger() (*mockLogger, *[]gomol.LogLevel) {
+	var (
+		logger = newDefaultMockLogger()
+		levels = []gomol.LogLevel{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		levels = append(levels, level)
+		return nil
+	}
+
+	return logger, &levels
+}
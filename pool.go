@@ -0,0 +1,40 @@
+package gomolreplay
+
+import (
+	"sync"
+
+	"github.com/aphistic/gomol"
+)
+
+// Pool is a set of reusable adapters which wrap the same logger and share
+// the same configuration. Reusing adapters (and their journal buffers)
+// avoids allocating a new adapter for each unit of work.
+type Pool struct {
+	pool sync.Pool
+}
+
+// NewPool creates a Pool whose adapters wrap the given logger and are
+// configured by the given config functions.
+func NewPool(logger gomol.WrappableLogger, configs ...ConfigFunc) *Pool {
+	return &Pool{
+		pool: sync.Pool{
+			New: func() interface{} {
+				return NewConfiguredAdapter(logger, configs...)
+			},
+		},
+	}
+}
+
+// Get returns an adapter with an empty journal.
+func (p *Pool) Get() *Adapter {
+	return p.pool.Get().(*Adapter)
+}
+
+// Finish calls Finish on the given adapter with the given result and then
+// returns the adapter to the pool. The adapter must not be used after this
+// method returns.
+func (p *Pool) Finish(a *Adapter, success bool) error {
+	err := a.Finish(success)
+	p.pool.Put(a)
+	return err
+}
@@ -0,0 +1,110 @@
+package gomolreplay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aphistic/gomol"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *ReplaySuite) TestFinishSuccess(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		adapter  = NewAdapter(logger, gomol.LevelDebug)
+		messages = []logArgs{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Debug("bar")
+	c.Assert(adapter.Finish(true), IsNil)
+	c.Assert(len(messages), Equals, 2)
+	c.Assert(adapter.journal.len(), Equals, 0)
+}
+
+func (s *ReplaySuite) TestFinishFailure(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		adapter  = NewConfiguredAdapter(logger, WithJournaledLevels(gomol.LevelDebug), WithFinishLevel(gomol.LevelWarning))
+		messages = []logArgs{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Debug("bar")
+	c.Assert(adapter.Finish(false), IsNil)
+	c.Assert(len(messages), Equals, 4)
+	c.Assert(messages[2].level, Equals, gomol.LevelWarning)
+	c.Assert(messages[3].level, Equals, gomol.LevelWarning)
+
+	// No longer replaying
+	adapter.Debug("baz")
+	c.Assert(len(messages), Equals, 5)
+	c.Assert(adapter.journal.len(), Equals, 1)
+}
+
+func (s *ReplaySuite) TestPoolReusesAdapters(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		pool     = NewPool(logger, WithJournaledLevels(gomol.LevelDebug))
+		messages = []logArgs{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	adapter := pool.Get()
+	adapter.Debug("foo")
+	c.Assert(pool.Finish(adapter, false), IsNil)
+	c.Assert(len(messages), Equals, 2)
+	c.Assert(messages[1].level, Equals, gomol.LevelError)
+
+	adapter = pool.Get()
+	c.Assert(adapter.journal.len(), Equals, 0)
+	adapter.Debug("bar")
+	c.Assert(pool.Finish(adapter, true), IsNil)
+	c.Assert(len(messages), Equals, 3)
+}
+
+//
+// Benchmarks
+
+func BenchmarkNewAdapterPerRequest(b *testing.B) {
+	logger := newDefaultMockLogger()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		adapter := NewAdapter(logger, gomol.LevelDebug)
+		simulateRequest(adapter)
+		adapter.Finish(true)
+	}
+}
+
+func BenchmarkPooledAdapterPerRequest(b *testing.B) {
+	pool := NewPool(newDefaultMockLogger(), WithJournaledLevels(gomol.LevelDebug))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		adapter := pool.Get()
+		simulateRequest(adapter)
+		pool.Finish(adapter, true)
+	}
+}
+
+func simulateRequest(adapter *Adapter) {
+	for j := 0; j < 50; j++ {
+		adapter.Debug("foo")
+	}
+}
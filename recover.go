@@ -0,0 +1,50 @@
+package gomolreplay
+
+import (
+	"runtime/debug"
+
+	"github.com/aphistic/gomol"
+)
+
+const (
+	// AttrPanicValue is an attribute assigned to the message logged by
+	// RecoverAndReplay. Its value is the recovered panic value.
+	AttrPanicValue = "panic-value"
+
+	// AttrPanicStack is an attribute assigned to the message logged by
+	// RecoverAndReplay. Its value is the stack trace of the panicking
+	// goroutine.
+	AttrPanicStack = "panic-stack"
+)
+
+// RecoverAndReplay recovers from a panic, replays the journal at the given
+// level, and then logs the panic value and stack trace at the given level to
+// the replay target (and to the wrapped logger, if it is a different logger).
+// The panic is then resumed unless the adapter was created with the
+// WithSwallowPanics config function. This method must be deferred directly
+// (e.g. `defer adapter.RecoverAndReplay(gomol.LevelError)`), otherwise it
+// will not be able to recover the panic.
+func (a *Adapter) RecoverAndReplay(level gomol.LogLevel) {
+	value := recover()
+	if value == nil {
+		return
+	}
+
+	a.replay(a.newReplay(level, "panic"))
+
+	attrs := gomol.NewAttrs().
+		SetAttr(AttrPanicValue, value).
+		SetAttr(AttrPanicStack, string(debug.Stack()))
+
+	// The panic message closes the replayed trace, so it is sent to the same
+	// logger as the replayed messages as well as to the wrapped logger.
+	a.replayTarget.Log(level, attrs, "recovered from panic: %v", value)
+
+	if a.replayTarget != a.base {
+		a.base.Log(level, attrs, "recovered from panic: %v", value)
+	}
+
+	if !a.swallowPanics {
+		panic(value)
+	}
+}
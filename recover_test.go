@@ -0,0 +1,126 @@
+package gomolreplay
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aphistic/gomol"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *ReplaySuite) TestRecoverAndReplay(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		adapter  = NewAdapter(logger, gomol.LevelDebug)
+		messages = []logArgs{}
+	)
+
+	logger.log = func(level gomol.LogLevel, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	c.Assert(func() {
+		defer adapter.RecoverAndReplay(gomol.LevelError)
+		adapter.Debug("foo")
+		panicky()
+	}, PanicMatches, "oops")
+
+	c.Assert(len(messages), Equals, 3)
+	c.Assert(messages[1].level, Equals, gomol.LevelError)
+	c.Assert(messages[1].msg, Equals, "foo")
+	c.Assert(messages[2].level, Equals, gomol.LevelError)
+	c.Assert(messages[2].a[0], Equals, "oops")
+	c.Assert(messages[2].attrs.GetAttr(AttrPanicValue), Equals, "oops")
+	c.Assert(strings.Contains(messages[2].attrs.GetAttr(AttrPanicStack).(string), "panicky"), Equals, true)
+}
+
+func (s *ReplaySuite) TestRecoverAndReplaySwallow(c *C) {
+	var (
+		logger  = newDefaultMockLogger()
+		adapter = NewConfiguredAdapter(logger, WithJournaledLevels(gomol.LevelDebug), WithSwallowPanics())
+		calls   = 0
+	)
+
+	logger.log = func(level gomol.LogLevel, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		calls++
+		return nil
+	}
+
+	func() {
+		defer adapter.RecoverAndReplay(gomol.LevelError)
+		panicky()
+	}()
+
+	c.Assert(calls, Equals, 1)
+}
+
+func (s *ReplaySuite) TestRecoverAndReplayTarget(c *C) {
+	var (
+		logger         = newDefaultMockLogger()
+		target         = newDefaultMockLogger()
+		adapter        = NewConfiguredAdapter(logger, WithJournaledLevels(gomol.LevelDebug), WithReplayTarget(target), WithSwallowPanics())
+		messages       = []string{}
+		targetMessages = []string{}
+	)
+
+	logger.log = func(level gomol.LogLevel, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, msg)
+		return nil
+	}
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, msg)
+		return nil
+	}
+
+	target.log = func(level gomol.LogLevel, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		targetMessages = append(targetMessages, msg)
+		return nil
+	}
+
+	target.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		targetMessages = append(targetMessages, msg)
+		return nil
+	}
+
+	func() {
+		defer adapter.RecoverAndReplay(gomol.LevelError)
+		adapter.Debug("foo")
+		panicky()
+	}()
+
+	c.Assert(messages, DeepEquals, []string{"foo", "recovered from panic: %v"})
+	c.Assert(targetMessages, DeepEquals, []string{"foo", "recovered from panic: %v"})
+}
+
+func (s *ReplaySuite) TestRecoverAndReplayNoPanic(c *C) {
+	var (
+		logger  = newDefaultMockLogger()
+		adapter = NewAdapter(logger, gomol.LevelDebug)
+		calls   = 0
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		calls++
+		return nil
+	}
+
+	func() {
+		defer adapter.RecoverAndReplay(gomol.LevelError)
+		adapter.Debug("foo")
+	}()
+
+	c.Assert(calls, Equals, 1)
+	c.Assert(adapter.replaying, IsNil)
+}
+
+func panicky() {
+	panic("oops")
+}
@@ -0,0 +1,546 @@
+package gomolreplay
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/aphistic/gomol"
+)
+
+const (
+	// AttrReplay is an attribute assigned to a message that has
+	// been replayed at a different log level. Its value is equal
+	// to the original log level.
+	AttrReplay = "replayed-from-level"
+
+	// AttrEvicted is an attribute assigned to the message sent at the
+	// start of a replay when journaled messages have been evicted. Its
+	// value is equal to the number of evicted messages.
+	AttrEvicted = "replay-evicted-messages"
+
+	// AttrElapsed is an attribute assigned to messages replayed by a
+	// watchdog. Its value is the time elapsed between the start of the
+	// watchdog and the replay.
+	AttrElapsed = "replay-elapsed"
+
+	// AttrEscalatedFrom is an attribute assigned to the marker message sent
+	// when a replay is escalated to a more severe level by an adapter created
+	// with WithEscalationMarkers. Its value is the identifier of the replay
+	// which was escalated.
+	AttrEscalatedFrom = "replay-escalated-from"
+
+	// AttrReplayID is the suggested key for WithReplayIDAttr.
+	AttrReplayID = "replay-id"
+
+	// AttrSequence is the suggested key for WithSequenceAttr.
+	AttrSequence = "replay-sequence"
+
+	// AttrJournalSize is the suggested key for WithJournalSizeAttr.
+	AttrJournalSize = "replay-journal-size"
+
+	// AttrReason is the suggested key for WithReasonAttr.
+	AttrReason = "replay-reason"
+
+	// AttrOriginalTime is the suggested key for WithReplayTimestamps.
+	AttrOriginalTime = "replay-original-time"
+
+	// AttrSkipped is an attribute assigned to the message sent at the start
+	// of a replay limited by ReplayLast or ReplayWithin when older journaled
+	// messages were skipped. Its value is equal to the number of skipped
+	// messages.
+	AttrSkipped = "replay-skipped-messages"
+)
+
+type (
+	// attrKeys holds the keys of the attributes assigned to replayed
+	// messages. An empty key disables the corresponding attribute.
+	attrKeys struct {
+		level        string
+		id           string
+		sequence     string
+		journalSize  string
+		reason       string
+		originalTime string
+	}
+
+	replayState struct {
+		level   gomol.LogLevel
+		target  gomol.WrappableLogger
+		id      string
+		reason  string
+		bracket bool
+		mapping LevelMapping
+		oneShot bool
+		full    bool
+		filter  Filter
+		hasLast bool
+		last    int
+		window  time.Duration
+
+		// attrs are assigned to every message sent as part of the replay,
+		// including messages replayed immediately after they are logged.
+		attrs *gomol.Attrs
+
+		// skipped holds the sequence numbers of the journaled messages which
+		// were not sent due to the replay's limits.
+		skipped map[uint64]struct{}
+
+		// pending, if non-nil, restricts the replay to the journaled messages
+		// with the given sequence numbers.
+		pending map[uint64]struct{}
+	}
+)
+
+// Replay will cause all of the messages previously logged at one of the
+// journaled levels to be re-set at the given level. All future messages
+// logged at one of the journaled levels will be replayed immediately. A
+// message logged concurrently with a call to Replay is replayed exactly once.
+// If messages were evicted from a bounded journal, a message stating the
+// number of evicted messages and the time span they covered is sent first.
+// Messages are replayed to the wrapped logger, or to the logger supplied
+// to WithReplayTarget.
+func (a *Adapter) Replay(level gomol.LogLevel) error {
+	return a.replay(a.newReplay(level, ""))
+}
+
+// ReplayBecause is similar to Replay, but records the given reason for the
+// replay. The reason is assigned to each replayed message as an attribute if
+// the adapter was created with WithReasonAttr.
+func (a *Adapter) ReplayBecause(level gomol.LogLevel, reason string) error {
+	return a.replay(a.newReplay(level, reason))
+}
+
+// ReplayTo is similar to Replay, but sends the replayed messages (and all
+// future messages logged at one of the journaled levels) to the given logger
+// instead of the wrapped logger.
+func (a *Adapter) ReplayTo(target gomol.WrappableLogger, level gomol.LogLevel) error {
+	replaying := a.newReplay(level, "")
+	replaying.target = target
+	return a.replay(replaying)
+}
+
+// ReplayFull is similar to Replay, but the entire journal is replayed even if
+// the adapter is already replaying (at any level) and even if the adapter was
+// created with WithEscalationMarkers.
+func (a *Adapter) ReplayFull(level gomol.LogLevel) error {
+	replaying := a.newReplay(level, "")
+	replaying.full = true
+	return a.replay(replaying)
+}
+
+// ReplayOnce is similar to Replay, but it does not cause future messages to
+// be replayed immediately. Instead, the journal is discarded after it is
+// replayed, and journaling continues as normal. If the adapter is already
+// replaying, the journal is replayed regardless of the level, and the adapter
+// stops replaying future messages immediately.
+func (a *Adapter) ReplayOnce(level gomol.LogLevel) error {
+	replaying := a.newReplay(level, "")
+	replaying.oneShot = true
+	return a.replay(replaying)
+}
+
+// ReplayLast is similar to Replay, but only the most recent n journaled
+// messages are replayed. If older messages were skipped, a message stating
+// the number of skipped messages and the time span they covered is sent
+// first. If n is zero, no journaled messages are replayed but the skipped
+// message is still sent. If n is negative, this method does nothing. A later
+// replay at the same or a less severe level sends only the skipped messages.
+func (a *Adapter) ReplayLast(level gomol.LogLevel, n int) error {
+	if n < 0 {
+		return nil
+	}
+
+	replaying := a.newReplay(level, "")
+	replaying.hasLast = true
+	replaying.last = n
+	return a.replay(replaying)
+}
+
+// ReplayWithin is similar to ReplayLast, but only the journaled messages
+// logged within the given duration of the adapter's current time are
+// replayed. A window which is not positive does not limit the replay.
+func (a *Adapter) ReplayWithin(level gomol.LogLevel, window time.Duration) error {
+	replaying := a.newReplay(level, "")
+	replaying.window = window
+	return a.replay(replaying)
+}
+
+// StopReplaying stops immediately replaying messages logged at one of the
+// journaled levels after a call to Replay. The journal is retained.
+func (a *Adapter) StopReplaying() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.replaying = nil
+}
+
+// ReplayWithReason is similar to Replay, but the replayed messages are
+// preceded by a header message and followed by a footer message which
+// state the reason for the replay, the number of replayed messages, and
+// the time span they cover. The reason is assigned to each replayed message
+// as an attribute (AttrReason unless another key was supplied to
+// WithReasonAttr). The given attributes (which may be nil) are assigned to
+// the header, the footer, and each message sent as part of this replay,
+// including messages which are replayed immediately after they are logged.
+func (a *Adapter) ReplayWithReason(level gomol.LogLevel, reason string, attrs *gomol.Attrs) error {
+	replaying := a.newReplay(level, reason)
+	replaying.bracket = true
+	replaying.attrs = attrs
+	return a.replay(replaying)
+}
+
+// newReplay creates a replay to the adapter's replay target.
+func (a *Adapter) newReplay(level gomol.LogLevel, reason string) *replayState {
+	return &replayState{level: level, target: a.replayTarget, reason: reason, oneShot: a.oneShot}
+}
+
+// replay implements Replay.
+func (a *Adapter) replay(replaying *replayState) error {
+	a.mutex.Lock()
+
+	if previous := a.replaying; covers(previous, replaying) {
+		if len(previous.skipped) == 0 {
+			a.mutex.Unlock()
+			return nil
+		}
+
+		// Every other message has already been sent by the previous replay
+		replaying.pending = previous.skipped
+	}
+
+	if a.attrKeys.id != "" || a.escalationMarkers {
+		replaying.id = a.replayID()
+	}
+
+	if previous := a.replaying; a.canEscalate(previous, replaying) {
+		a.replaying = replaying
+		journalSize := a.journal.len()
+		a.mutex.Unlock()
+
+		return a.escalate(previous, replaying, a.batchAttrs(replaying, journalSize))
+	}
+
+	a.journal.expireAll(a.clock.Now())
+	journal := a.journal.messages()
+	journalSize := len(journal)
+
+	if replaying.pending != nil {
+		journal = pendingMessages(journal, replaying.pending)
+	}
+
+	// Skip older messages while holding the lock so that a later replay
+	// can send exactly the messages skipped by this one.
+	journal, skipped := a.skipOlder(replaying, journal)
+	replaying.skipped = sequenceSet(skipped)
+
+	if replaying.mapping != nil {
+		// Send the header, footer, and eviction notice at the most severe level
+		// at which a message of this replay is sent.
+		for _, message := range journal {
+			if level := replaying.mapping(message.level); level < replaying.level {
+				replaying.level = level
+			}
+		}
+	}
+
+	evicted, evictedFrom, evictedTo := a.journal.evicted, a.journal.evictedFrom, a.journal.evictedTo
+
+	switch {
+	case replaying.filter != nil:
+		// A filtered replay neither consumes the journal nor causes future
+		// messages to be replayed immediately.
+	case replaying.oneShot:
+		a.journal.reset()
+		a.replaying = nil
+	default:
+		a.replaying = replaying
+	}
+
+	a.mutex.Unlock()
+
+	batchAttrs := a.batchAttrs(replaying, journalSize)
+
+	if replaying.filter != nil {
+		journal = filterMessages(journal, replaying.filter)
+	}
+
+	if replaying.bracket {
+		if err := a.replayBracket(replaying, batchAttrs, journal, "replaying"); err != nil {
+			return err
+		}
+	}
+
+	if evicted > 0 {
+		if err := replaying.target.LogWithTime(
+			replaying.level,
+			a.clock.Now(),
+			mergeAttrs(batchAttrs, nil).SetAttr(AttrEvicted, evicted),
+			"%d journaled messages logged between %s and %s (%s) were evicted before replay",
+			evicted,
+			evictedFrom.Format(time.RFC3339Nano),
+			evictedTo.Format(time.RFC3339Nano),
+			evictedTo.Sub(evictedFrom),
+		); err != nil {
+			return err
+		}
+	}
+
+	if len(skipped) > 0 {
+		if err := a.replaySkipped(replaying, batchAttrs, skipped); err != nil {
+			return err
+		}
+	}
+
+	for _, message := range journal {
+		if err := a.replayMessage(replaying, message, batchAttrs); err != nil {
+			return err
+		}
+	}
+
+	if replaying.bracket {
+		return a.replayBracket(replaying, batchAttrs, journal, "finished replaying")
+	}
+
+	return nil
+}
+
+// covers determines if the previous replay has sent every message which the
+// given replay would send, other than those it skipped due to its limits. A
+// replay to a different logger sends messages that logger has not received,
+// and full and one-shot replays are never covered.
+func covers(previous, replaying *replayState) bool {
+	if previous == nil || replaying.full || replaying.oneShot {
+		return false
+	}
+
+	if previous.target != replaying.target {
+		return false
+	}
+
+	return previous.mapping == nil && replaying.mapping == nil && previous.level <= replaying.level
+}
+
+// canEscalate determines if the given replay should be sent as a marker
+// referring to the previous replay instead of replaying the entire journal.
+func (a *Adapter) canEscalate(previous, replaying *replayState) bool {
+	if !a.escalationMarkers || previous == nil || replaying.full || replaying.oneShot || replaying.filter != nil {
+		return false
+	}
+
+	// A replay with a header and footer always sends them around the journal
+	if replaying.bracket {
+		return false
+	}
+
+	if previous.limited() || replaying.limited() {
+		return false
+	}
+
+	// The marker refers to messages sent by the previous replay, which is
+	// only useful if they were sent to the same logger.
+	if previous.target != replaying.target {
+		return false
+	}
+
+	return previous.mapping == nil && replaying.mapping == nil
+}
+
+// escalate sends a marker message stating that the given previous replay has
+// been escalated to the level of the given replay. Every message in the journal
+// has already been sent as part of the previous replay.
+func (a *Adapter) escalate(previous, replaying *replayState, batchAttrs *gomol.Attrs) error {
+	return replaying.target.LogWithTime(
+		replaying.level,
+		a.clock.Now(),
+		mergeAttrs(batchAttrs, nil).SetAttr(AttrEscalatedFrom, previous.id),
+		"replay %s escalated from %s to %s; previously replayed messages are not repeated",
+		previous.id,
+		previous.level,
+		replaying.level,
+	)
+}
+
+// limited determines if the replay sends only the most recent messages.
+func (r *replayState) limited() bool {
+	return r.hasLast || r.window > 0
+}
+
+// skipOlder splits the given messages into those which are sent by the given
+// replay and the older messages which are skipped due to the replay's limits.
+func (a *Adapter) skipOlder(replaying *replayState, messages []*logMessage) (kept, skipped []*logMessage) {
+	if replaying.hasLast && len(messages) > replaying.last {
+		skipped = messages[:len(messages)-replaying.last]
+		messages = messages[len(messages)-replaying.last:]
+	}
+
+	if replaying.window <= 0 {
+		return messages, skipped
+	}
+
+	cutoff := a.clock.Now().Add(-replaying.window)
+	kept = make([]*logMessage, 0, len(messages))
+	for _, message := range messages {
+		if message.ts.Before(cutoff) {
+			skipped = append(skipped, message)
+		} else {
+			kept = append(kept, message)
+		}
+	}
+
+	return kept, skipped
+}
+
+// pendingMessages returns the messages whose sequence numbers are in the
+// given set.
+func pendingMessages(messages []*logMessage, pending map[uint64]struct{}) []*logMessage {
+	kept := make([]*logMessage, 0, len(pending))
+	for _, message := range messages {
+		if _, ok := pending[message.seq]; ok {
+			kept = append(kept, message)
+		}
+	}
+
+	return kept
+}
+
+// sequenceSet returns the set of sequence numbers of the given messages, or
+// nil if there are no messages.
+func sequenceSet(messages []*logMessage) map[uint64]struct{} {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	set := make(map[uint64]struct{}, len(messages))
+	for _, message := range messages {
+		set[message.seq] = struct{}{}
+	}
+
+	return set
+}
+
+// replaySkipped sends a message stating the number of messages skipped by a
+// replay and the time span they covered.
+func (a *Adapter) replaySkipped(replaying *replayState, batchAttrs *gomol.Attrs, skipped []*logMessage) error {
+	from, to := timeSpan(skipped)
+
+	return replaying.target.LogWithTime(
+		replaying.level,
+		a.clock.Now(),
+		mergeAttrs(batchAttrs, nil).SetAttr(AttrSkipped, len(skipped)),
+		"%d older journaled messages logged between %s and %s (%s) were skipped",
+		len(skipped),
+		from.Format(time.RFC3339Nano),
+		to.Format(time.RFC3339Nano),
+		to.Sub(from),
+	)
+}
+
+// replayBracket sends the header or footer message of a replay.
+func (a *Adapter) replayBracket(replaying *replayState, batchAttrs *gomol.Attrs, journal []*logMessage, prefix string) error {
+	from, to := timeSpan(journal)
+
+	return replaying.target.LogWithTime(
+		replaying.level,
+		a.clock.Now(),
+		mergeAttrs(batchAttrs, nil),
+		"%s %d journaled messages logged between %s and %s (%s): %s",
+		prefix,
+		len(journal),
+		from.Format(time.RFC3339Nano),
+		to.Format(time.RFC3339Nano),
+		to.Sub(from),
+		replaying.reason,
+	)
+}
+
+// timeSpan returns the earliest and latest times at which one of the given
+// messages was logged.
+func timeSpan(messages []*logMessage) (from, to time.Time) {
+	for i, message := range messages {
+		if i == 0 || message.ts.Before(from) {
+			from = message.ts
+		}
+
+		if i == 0 || message.ts.After(to) {
+			to = message.ts
+		}
+	}
+
+	return from, to
+}
+
+// batchAttrs returns the attributes shared by every message sent as part
+// of the given replay.
+func (a *Adapter) batchAttrs(replaying *replayState, journalSize int) *gomol.Attrs {
+	attrs := mergeAttrs(nil, replaying.attrs)
+	setAttr(attrs, a.attrKeys.id, replaying.id)
+	setAttr(attrs, a.attrKeys.journalSize, journalSize)
+
+	if replaying.reason != "" {
+		reasonKey := a.attrKeys.reason
+		if reasonKey == "" && replaying.bracket {
+			reasonKey = AttrReason
+		}
+
+		setAttr(attrs, reasonKey, replaying.reason)
+	}
+
+	return attrs
+}
+
+func (a *Adapter) replayMessage(replaying *replayState, message *logMessage, batchAttrs *gomol.Attrs) error {
+	level := replaying.level
+	if replaying.mapping != nil {
+		level = replaying.mapping(message.level)
+	}
+
+	if a.originalLevel {
+		level = message.level
+	}
+
+	attrs := mergeAttrs(message.attrs, batchAttrs)
+	setAttr(attrs, a.attrKeys.level, message.level)
+	setAttr(attrs, a.attrKeys.sequence, message.seq)
+
+	ts := message.ts
+	if a.attrKeys.originalTime != "" {
+		ts = a.clock.Now()
+		setAttr(attrs, a.attrKeys.originalTime, message.ts)
+	}
+
+	return replaying.target.LogWithTime(level, ts, attrs, message.msg, message.args...)
+}
+
+// mergeAttrs returns a new set of attributes containing the attributes
+// of both inputs, either of which may be nil.
+func mergeAttrs(attrs, extra *gomol.Attrs) *gomol.Attrs {
+	merged := gomol.NewAttrs()
+
+	if attrs != nil {
+		merged.MergeAttrs(attrs)
+	}
+
+	if extra != nil {
+		merged.MergeAttrs(extra)
+	}
+
+	return merged
+}
+
+// setAttr sets the given attribute unless the key is empty.
+func setAttr(attrs *gomol.Attrs, key string, value interface{}) {
+	if key != "" {
+		attrs.SetAttr(key, value)
+	}
+}
+
+// randomID returns a random 16-character hex string.
+func randomID() string {
+	buffer := make([]byte, 8)
+	if _, err := rand.Read(buffer); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(buffer)
+}
@@ -0,0 +1,622 @@
+package gomolreplay
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aphistic/gomol"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *ReplaySuite) TestReplayLevelAttr(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		adapter  = NewConfiguredAdapter(logger, WithJournaledLevels(gomol.LevelDebug), WithReplayLevelAttr("level"))
+		messages = []logArgs{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Replay(gomol.LevelError)
+
+	c.Assert(len(messages), Equals, 2)
+	c.Assert(messages[1].attrs.GetAttr("level"), Equals, gomol.LevelDebug)
+	c.Assert(messages[1].attrs.GetAttr(AttrReplay), IsNil)
+}
+
+func (s *ReplaySuite) TestReplayExtraAttrs(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		clock    = newMockClock(1000)
+		ids      = 0
+		messages = []logArgs{}
+		adapter  = NewConfiguredAdapter(
+			logger,
+			withClock(clock),
+			withReplayID(func() string { ids++; return fmt.Sprintf("id-%d", ids) }),
+			WithJournaledLevels(gomol.LevelDebug),
+			WithMaxEntries(2),
+			WithReplayIDAttr(AttrReplayID),
+			WithSequenceAttr(AttrSequence),
+			WithJournalSizeAttr(AttrJournalSize),
+			WithReasonAttr(AttrReason),
+		)
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		if level != gomol.LevelDebug {
+			messages = append(messages, logArgs{level, attrs, msg, a})
+		}
+
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Debug("bar")
+	adapter.Debug("baz")
+	adapter.Replay(gomol.LevelWarning)
+	adapter.Debug("bnk")
+	adapter.Finish(false)
+
+	c.Assert(len(messages), Equals, 7)
+
+	// Eviction notice, bar, baz, bnk (sticky)
+	for i := 0; i < 4; i++ {
+		c.Assert(messages[i].attrs.GetAttr(AttrReplayID), Equals, "id-1")
+		c.Assert(messages[i].attrs.GetAttr(AttrJournalSize), Equals, 2)
+		c.Assert(messages[i].attrs.GetAttr(AttrReason), IsNil)
+	}
+
+	c.Assert(messages[0].attrs.GetAttr(AttrSequence), IsNil)
+	c.Assert(messages[1].attrs.GetAttr(AttrSequence), Equals, uint64(2))
+	c.Assert(messages[2].attrs.GetAttr(AttrSequence), Equals, uint64(3))
+	c.Assert(messages[3].attrs.GetAttr(AttrSequence), Equals, uint64(4))
+
+	// Eviction notice, baz, bnk
+	for i := 4; i < 7; i++ {
+		c.Assert(messages[i].level, Equals, gomol.LevelError)
+		c.Assert(messages[i].attrs.GetAttr(AttrReplayID), Equals, "id-2")
+		c.Assert(messages[i].attrs.GetAttr(AttrReason), Equals, "unsuccessful finish")
+	}
+}
+
+func (s *ReplaySuite) TestReplayBecause(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		adapter  = NewConfiguredAdapter(logger, WithJournaledLevels(gomol.LevelDebug), WithReasonAttr("why"))
+		messages = []logArgs{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		if level == gomol.LevelWarning {
+			messages = append(messages, logArgs{level, attrs, msg, a})
+		}
+
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.ReplayBecause(gomol.LevelWarning, "slow request")
+	adapter.Debug("bar")
+
+	c.Assert(len(messages), Equals, 2)
+	c.Assert(messages[0].msg, Equals, "foo")
+	c.Assert(messages[1].msg, Equals, "bar")
+	c.Assert(messages[0].attrs.GetAttr("why"), Equals, "slow request")
+	c.Assert(messages[1].attrs.GetAttr("why"), Equals, "slow request")
+}
+
+func (s *ReplaySuite) TestReplayWithReason(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		clock    = newMockClock(1000)
+		adapter  = newAdapterWithClock(logger, clock, gomol.LevelDebug)
+		messages = []logArgs{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		if level == gomol.LevelError {
+			messages = append(messages, logArgs{level, attrs, msg, a})
+		}
+
+		return nil
+	}
+
+	adapter.Debug("foo")
+	clock.advance(2000)
+	adapter.Debug("bar")
+	clock.advance(2000)
+	adapter.ReplayWithReason(gomol.LevelError, "upstream timeout", gomol.NewAttrsFromMap(map[string]interface{}{"x": "y"}))
+	adapter.Debug("baz")
+
+	c.Assert(len(messages), Equals, 5)
+
+	// Header, foo, bar, footer, baz (sticky)
+	for i, msg := range map[int]string{1: "foo", 2: "bar", 4: "baz"} {
+		c.Assert(messages[i].msg, Equals, msg)
+		c.Assert(messages[i].attrs.GetAttr(AttrReason), Equals, "upstream timeout")
+	}
+
+	for i := 0; i < 5; i++ {
+		c.Assert(messages[i].attrs.GetAttr("x"), Equals, "y")
+	}
+
+	c.Assert(messages[0].a, DeepEquals, []interface{}{
+		"replaying",
+		2,
+		time.Unix(1, 0).Format(time.RFC3339Nano),
+		time.Unix(3, 0).Format(time.RFC3339Nano),
+		2 * time.Second,
+		"upstream timeout",
+	})
+
+	c.Assert(messages[3].a[0], Equals, "finished replaying")
+	c.Assert(messages[3].attrs.GetAttr(AttrReason), Equals, "upstream timeout")
+}
+
+func (s *ReplaySuite) TestReplayOnce(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		adapter  = NewAdapter(logger, gomol.LevelDebug)
+		messages = []logArgs{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Debug("bar")
+	adapter.ReplayOnce(gomol.LevelWarning)
+	adapter.Debug("baz")
+	adapter.ReplayOnce(gomol.LevelWarning)
+
+	c.Assert(len(messages), Equals, 6)
+	c.Assert(adapter.journal.len(), Equals, 0)
+
+	for i, level := range []gomol.LogLevel{gomol.LevelDebug, gomol.LevelDebug, gomol.LevelWarning, gomol.LevelWarning, gomol.LevelDebug, gomol.LevelWarning} {
+		c.Assert(messages[i].level, Equals, level)
+	}
+
+	for i, msg := range []string{"foo", "bar", "foo", "bar", "baz", "baz"} {
+		c.Assert(messages[i].msg, Equals, msg)
+	}
+}
+
+func (s *ReplaySuite) TestOneShotReplayMode(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		adapter  = NewConfiguredAdapter(logger, WithJournaledLevels(gomol.LevelDebug), WithOneShotReplay(), WithTriggerLevel(gomol.LevelError))
+		messages = []logArgs{}
+	)
+
+	logger.log = func(level gomol.LogLevel, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Error("bar")
+	adapter.Debug("baz")
+
+	c.Assert(len(messages), Equals, 4)
+	c.Assert(messages[1].level, Equals, gomol.LevelError)
+	c.Assert(messages[1].msg, Equals, "foo")
+	c.Assert(messages[3].level, Equals, gomol.LevelDebug)
+	c.Assert(adapter.journal.len(), Equals, 1)
+}
+
+func (s *ReplaySuite) TestReplayOnceWhileReplaying(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		adapter  = NewConfiguredAdapter(logger, WithJournaledLevels(gomol.LevelDebug), WithBufferOnly())
+		messages = []logArgs{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Replay(gomol.LevelError)
+	adapter.ReplayOnce(gomol.LevelWarning)
+
+	c.Assert(len(messages), Equals, 2)
+	c.Assert(messages[1].level, Equals, gomol.LevelWarning)
+	c.Assert(messages[1].msg, Equals, "foo")
+	c.Assert(adapter.replaying, IsNil)
+	c.Assert(adapter.journal.len(), Equals, 0)
+
+	adapter.Debug("bar")
+	adapter.Replay(gomol.LevelWarning)
+	adapter.ReplayOnce(gomol.LevelError)
+
+	c.Assert(len(messages), Equals, 4)
+	c.Assert(messages[3].level, Equals, gomol.LevelError)
+	c.Assert(messages[3].msg, Equals, "bar")
+	c.Assert(adapter.replaying, IsNil)
+	c.Assert(adapter.journal.len(), Equals, 0)
+
+	// Future messages are journaled as normal
+	adapter.Debug("baz")
+	c.Assert(len(messages), Equals, 4)
+	c.Assert(adapter.journal.len(), Equals, 1)
+}
+
+func (s *ReplaySuite) TestOneShotReplayModeReplayTo(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		target   = newDefaultMockLogger()
+		adapter  = NewConfiguredAdapter(logger, WithJournaledLevels(gomol.LevelDebug), WithOneShotReplay())
+		messages = []string{}
+	)
+
+	target.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, msg)
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.ReplayTo(target, gomol.LevelWarning)
+	adapter.Debug("bar")
+
+	c.Assert(messages, DeepEquals, []string{"foo"})
+	c.Assert(adapter.replaying, IsNil)
+	c.Assert(adapter.journal.len(), Equals, 1)
+}
+
+func (s *ReplaySuite) TestStopReplaying(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		adapter  = NewAdapter(logger, gomol.LevelDebug)
+		messages = []logArgs{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Replay(gomol.LevelWarning)
+	adapter.StopReplaying()
+	adapter.Debug("bar")
+
+	c.Assert(len(messages), Equals, 3)
+	c.Assert(messages[2].level, Equals, gomol.LevelDebug)
+	c.Assert(adapter.journal.len(), Equals, 2)
+
+	// Can replay again at the same level
+	adapter.Replay(gomol.LevelWarning)
+	c.Assert(len(messages), Equals, 5)
+}
+
+func (s *ReplaySuite) TestEscalationMarkers(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		ids      = 0
+		messages = []logArgs{}
+		adapter  = NewConfiguredAdapter(
+			logger,
+			withReplayID(func() string { ids++; return fmt.Sprintf("id-%d", ids) }),
+			WithJournaledLevels(gomol.LevelDebug),
+			WithEscalationMarkers(),
+		)
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Debug("bar")
+	adapter.Replay(gomol.LevelWarning)
+	adapter.Replay(gomol.LevelError)
+	adapter.Debug("baz")
+
+	c.Assert(len(messages), Equals, 7)
+	c.Assert(messages[4].level, Equals, gomol.LevelError)
+	c.Assert(messages[4].attrs.GetAttr(AttrEscalatedFrom), Equals, "id-1")
+	c.Assert(messages[4].a, DeepEquals, []interface{}{"id-1", gomol.LevelWarning, gomol.LevelError})
+	c.Assert(messages[6].level, Equals, gomol.LevelError)
+	c.Assert(messages[6].msg, Equals, "baz")
+
+	adapter.ReplayFull(gomol.LevelFatal)
+	c.Assert(len(messages), Equals, 10)
+
+	for i, msg := range []string{"foo", "bar", "baz"} {
+		c.Assert(messages[i+7].level, Equals, gomol.LevelFatal)
+		c.Assert(messages[i+7].msg, Equals, msg)
+	}
+}
+
+func (s *ReplaySuite) TestReplayTimestamps(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		clock    = newMockClock(1000)
+		adapter  = NewConfiguredAdapter(logger, withClock(clock), WithJournaledLevels(gomol.LevelDebug), WithReplayTimestamps(AttrOriginalTime))
+		messages = []time.Time{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		if level == gomol.LevelError {
+			c.Assert(attrs.GetAttr(AttrOriginalTime), Equals, time.Unix(1, 0))
+			messages = append(messages, ts)
+		}
+
+		return nil
+	}
+
+	adapter.Debug("foo")
+	clock.advance(5000)
+	adapter.Replay(gomol.LevelError)
+
+	c.Assert(messages, DeepEquals, []time.Time{time.Unix(6, 0)})
+}
+
+func (s *ReplaySuite) TestRandomID(c *C) {
+	id1 := randomID()
+	id2 := randomID()
+
+	c.Assert(id1, HasLen, 16)
+	c.Assert(id2, HasLen, 16)
+	c.Assert(id1, Not(Equals), id2)
+}
+
+func (s *ReplaySuite) TestEscalationMarkersReplayWithReason(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		adapter  = NewConfiguredAdapter(logger, WithJournaledLevels(gomol.LevelDebug), WithBufferOnly(), WithEscalationMarkers())
+		messages = []string{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		if level == gomol.LevelError {
+			messages = append(messages, msg)
+		}
+
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Replay(gomol.LevelWarning)
+	adapter.ReplayWithReason(gomol.LevelError, "upstream timeout", nil)
+
+	c.Assert(messages, DeepEquals, []string{
+		"%s %d journaled messages logged between %s and %s (%s): %s",
+		"foo",
+		"%s %d journaled messages logged between %s and %s (%s): %s",
+	})
+}
+
+func (s *ReplaySuite) TestEscalationMarkersDifferentTarget(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		target   = newDefaultMockLogger()
+		adapter  = NewConfiguredAdapter(logger, WithJournaledLevels(gomol.LevelDebug), WithBufferOnly(), WithEscalationMarkers())
+		messages = []string{}
+	)
+
+	target.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, msg)
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Debug("bar")
+	adapter.Replay(gomol.LevelWarning)
+	adapter.ReplayTo(target, gomol.LevelError)
+
+	// The target has not received the journal, so it is replayed in full
+	c.Assert(messages, DeepEquals, []string{"foo", "bar"})
+}
+
+func (s *ReplaySuite) TestReplayLast(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		clock    = newMockClock(1000)
+		adapter  = NewConfiguredAdapter(logger, withClock(clock), WithJournaledLevels(gomol.LevelDebug), WithBufferOnly())
+		messages = []logArgs{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	for _, msg := range []string{"foo", "bar", "baz", "bnk"} {
+		adapter.Debug(msg)
+		clock.advance(1000)
+	}
+
+	adapter.ReplayLast(gomol.LevelError, 2)
+	adapter.Debug("qux")
+
+	c.Assert(len(messages), Equals, 4)
+	c.Assert(messages[0].level, Equals, gomol.LevelError)
+	c.Assert(messages[0].attrs.GetAttr(AttrSkipped), Equals, 2)
+	c.Assert(messages[0].a, DeepEquals, []interface{}{
+		2,
+		time.Unix(1, 0).Format(time.RFC3339Nano),
+		time.Unix(2, 0).Format(time.RFC3339Nano),
+		time.Second,
+	})
+
+	// Future messages are replayed immediately
+	for i, msg := range []string{"baz", "bnk", "qux"} {
+		c.Assert(messages[i+1].level, Equals, gomol.LevelError)
+		c.Assert(messages[i+1].msg, Equals, msg)
+	}
+
+	c.Assert(adapter.journal.len(), Equals, 5)
+}
+
+func (s *ReplaySuite) TestReplayLastNothingSkipped(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		adapter  = NewConfiguredAdapter(logger, WithJournaledLevels(gomol.LevelDebug), WithBufferOnly())
+		messages = []logArgs{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Debug("bar")
+	adapter.ReplayLast(gomol.LevelError, 5)
+
+	c.Assert(len(messages), Equals, 2)
+	c.Assert(messages[0].msg, Equals, "foo")
+	c.Assert(messages[1].msg, Equals, "bar")
+}
+
+func (s *ReplaySuite) TestReplayWithin(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		clock    = newMockClock(1000)
+		adapter  = NewConfiguredAdapter(logger, withClock(clock), WithJournaledLevels(gomol.LevelDebug), WithBufferOnly(), WithOneShotReplay())
+		messages = []logArgs{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	for _, msg := range []string{"foo", "bar", "baz", "bnk"} {
+		adapter.Debug(msg)
+		clock.advance(1000)
+	}
+
+	adapter.ReplayWithin(gomol.LevelError, 2*time.Second)
+
+	c.Assert(len(messages), Equals, 3)
+	c.Assert(messages[0].attrs.GetAttr(AttrSkipped), Equals, 2)
+	c.Assert(messages[1].msg, Equals, "baz")
+	c.Assert(messages[2].msg, Equals, "bnk")
+	c.Assert(adapter.journal.len(), Equals, 0)
+}
+
+func (s *ReplaySuite) TestReplayLastNotEscalated(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		adapter  = NewConfiguredAdapter(logger, WithJournaledLevels(gomol.LevelDebug), WithBufferOnly(), WithEscalationMarkers())
+		messages = []logArgs{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Debug("bar")
+	adapter.ReplayLast(gomol.LevelWarning, 1)
+	adapter.Replay(gomol.LevelError)
+
+	c.Assert(len(messages), Equals, 4)
+	c.Assert(messages[1].msg, Equals, "bar")
+	c.Assert(messages[2].level, Equals, gomol.LevelError)
+	c.Assert(messages[2].msg, Equals, "foo")
+	c.Assert(messages[3].msg, Equals, "bar")
+}
+
+func (s *ReplaySuite) TestReplayAfterReplayLast(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		adapter  = NewConfiguredAdapter(logger, WithJournaledLevels(gomol.LevelDebug), WithBufferOnly())
+		messages = []string{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, msg)
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Debug("bar")
+	adapter.ReplayLast(gomol.LevelError, 1)
+	c.Assert(len(messages), Equals, 2)
+	c.Assert(messages[1], Equals, "bar")
+
+	// Only the skipped history is replayed
+	adapter.Debug("baz")
+	adapter.Replay(gomol.LevelError)
+	c.Assert(messages[2:], DeepEquals, []string{"baz", "foo"})
+
+	adapter.Replay(gomol.LevelError)
+	c.Assert(len(messages), Equals, 4)
+
+	adapter.ReplayFull(gomol.LevelError)
+	c.Assert(messages[4:], DeepEquals, []string{"foo", "bar", "baz"})
+}
+
+func (s *ReplaySuite) TestReplayAfterReplayWithin(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		clock    = newMockClock(10000)
+		adapter  = NewConfiguredAdapter(logger, withClock(clock), WithJournaledLevels(gomol.LevelDebug), WithBufferOnly())
+		messages = []string{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, msg)
+		return nil
+	}
+
+	// The skipped messages are not contiguous in the journal
+	adapter.LogWithTime(gomol.LevelDebug, time.Unix(1, 0), nil, "foo")
+	adapter.LogWithTime(gomol.LevelDebug, time.Unix(9, 0), nil, "bar")
+	adapter.LogWithTime(gomol.LevelDebug, time.Unix(2, 0), nil, "baz")
+	adapter.ReplayWithin(gomol.LevelError, 5*time.Second)
+	c.Assert(messages[1:], DeepEquals, []string{"bar"})
+
+	adapter.Replay(gomol.LevelWarning)
+	c.Assert(messages[2:], DeepEquals, []string{"foo", "baz"})
+
+	adapter.Replay(gomol.LevelWarning)
+	c.Assert(len(messages), Equals, 4)
+}
+
+func (s *ReplaySuite) TestReplayLastZero(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		adapter  = NewConfiguredAdapter(logger, WithJournaledLevels(gomol.LevelDebug), WithBufferOnly())
+		messages = []logArgs{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Debug("bar")
+
+	adapter.ReplayLast(gomol.LevelError, -1)
+	c.Assert(len(messages), Equals, 0)
+	c.Assert(adapter.replaying, IsNil)
+
+	adapter.ReplayLast(gomol.LevelError, 0)
+	c.Assert(len(messages), Equals, 1)
+	c.Assert(messages[0].attrs.GetAttr(AttrSkipped), Equals, 2)
+
+	adapter.Debug("baz")
+	c.Assert(len(messages), Equals, 2)
+	c.Assert(messages[1].msg, Equals, "baz")
+}
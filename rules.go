@@ -0,0 +1,83 @@
+package gomolreplay
+
+import (
+	"reflect"
+	"regexp"
+
+	"github.com/aphistic/gomol"
+)
+
+type (
+	// Rule determines what is done with a logged message from its level,
+	// its message template, and its attributes (which may be nil). A rule
+	// which does not apply to the message returns DispositionDefault.
+	Rule func(level gomol.LogLevel, msg string, attrs *gomol.Attrs) Disposition
+
+	// Disposition determines whether a logged message is journaled and
+	// whether it is forwarded to the wrapped logger.
+	Disposition int
+)
+
+const (
+	// DispositionDefault defers to the next rule. If no rule applies, a
+	// message is journaled if it is logged at one of the journaled levels,
+	// and is forwarded unless it is journaled by a buffer-only adapter.
+	DispositionDefault Disposition = iota
+
+	// DispositionJournal journals the message without forwarding it.
+	DispositionJournal
+
+	// DispositionForward forwards the message without journaling it.
+	DispositionForward
+
+	// DispositionBoth journals the message and forwards it.
+	DispositionBoth
+
+	// DispositionDrop neither journals nor forwards the message.
+	DispositionDrop
+)
+
+// AttrRule creates a rule which applies the given disposition to messages
+// with an attribute with the given key and value.
+func AttrRule(key string, value interface{}, disposition Disposition) Rule {
+	return func(level gomol.LogLevel, msg string, attrs *gomol.Attrs) Disposition {
+		if attrs != nil && reflect.DeepEqual(attrs.GetAttr(key), value) {
+			return disposition
+		}
+
+		return DispositionDefault
+	}
+}
+
+// MessageRule creates a rule which applies the given disposition to messages
+// whose template (the format string, not the formatted message) matches the
+// given pattern.
+func MessageRule(pattern *regexp.Regexp, disposition Disposition) Rule {
+	return func(level gomol.LogLevel, msg string, attrs *gomol.Attrs) Disposition {
+		if pattern.MatchString(msg) {
+			return disposition
+		}
+
+		return DispositionDefault
+	}
+}
+
+// route determines whether a message should be journaled and whether it
+// should be forwarded to the wrapped logger.
+func (a *Adapter) route(level gomol.LogLevel, msg string, attrs *gomol.Attrs) (journaled, forwarded bool) {
+	for _, rule := range a.rules {
+		switch rule(level, msg, attrs) {
+		case DispositionJournal:
+			return true, false
+		case DispositionForward:
+			return false, true
+		case DispositionBoth:
+			return true, true
+		case DispositionDrop:
+			return false, false
+		}
+	}
+
+	journaled = a.shouldJournal(level)
+	return journaled, !journaled || !a.bufferOnly
+}
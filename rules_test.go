@@ -0,0 +1,87 @@
+package gomolreplay
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/aphistic/gomol"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *ReplaySuite) TestRules(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		messages = []string{}
+		adapter  = NewConfiguredAdapter(
+			logger,
+			WithJournaledLevels(gomol.LevelDebug),
+			WithRules(
+				MessageRule(regexp.MustCompile("^health check"), DispositionDrop),
+				AttrRule("component", "db", DispositionBoth),
+				AttrRule("component", "cache", DispositionForward),
+				AttrRule("component", "auth", DispositionJournal),
+			),
+		)
+	)
+
+	logger.log = func(level gomol.LogLevel, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, msg)
+		return nil
+	}
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, msg)
+		return nil
+	}
+
+	adapter.Debug("health check from %s")
+	adapter.Info("health check from %s")
+	adapter.Debug("foo")
+	adapter.Infom(gomol.NewAttrsFromMap(map[string]interface{}{"component": "db"}), "bar")
+	adapter.Debugm(gomol.NewAttrsFromMap(map[string]interface{}{"component": "cache"}), "baz")
+	adapter.Infom(gomol.NewAttrsFromMap(map[string]interface{}{"component": "auth"}), "bnk")
+	adapter.Info("qux")
+
+	c.Assert(messages, DeepEquals, []string{"foo", "bar", "baz", "qux"})
+
+	c.Assert(adapter.journal.len(), Equals, 3)
+	c.Assert(adapter.journal.at(0).msg, Equals, "foo")
+	c.Assert(adapter.journal.at(1).msg, Equals, "bar")
+	c.Assert(adapter.journal.at(2).msg, Equals, "bnk")
+}
+
+func (s *ReplaySuite) TestRulesBufferOnly(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		messages = []string{}
+		adapter  = NewConfiguredAdapter(
+			logger,
+			WithJournaledLevels(gomol.LevelDebug),
+			WithBufferOnly(),
+			WithRules(func(level gomol.LogLevel, msg string, attrs *gomol.Attrs) Disposition {
+				if level == gomol.LevelDebug && msg == "bar" {
+					return DispositionBoth
+				}
+
+				return DispositionDefault
+			}),
+		)
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, msg)
+		return nil
+	}
+
+	adapter.Debug("foo")
+	adapter.Debug("bar")
+	c.Assert(messages, DeepEquals, []string{"bar"})
+	c.Assert(adapter.journal.len(), Equals, 2)
+}
+
+func (s *ReplaySuite) TestAttrRuleNilAttrs(c *C) {
+	rule := AttrRule("component", "db", DispositionDrop)
+	c.Assert(rule(gomol.LevelDebug, "foo", nil), Equals, DispositionDefault)
+	c.Assert(rule(gomol.LevelDebug, "foo", gomol.NewAttrs()), Equals, DispositionDefault)
+}
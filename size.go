@@ -0,0 +1,54 @@
+package gomolreplay
+
+import (
+	"fmt"
+	"time"
+)
+
+// messageOverhead is a rough estimate of the fixed cost of a journaled
+// message: the logMessage struct, its slice headers, and the journal slot.
+const messageOverhead = 128
+
+// estimateSize returns an approximation of the number of bytes retained
+// by the given message while it is held in the journal. Arguments and
+// attribute values which are not strings or scalars are formatted in
+// order to size them, so their Error or String methods are called when
+// the message is logged even if the message is never replayed.
+func estimateSize(message *logMessage) int64 {
+	size := int64(messageOverhead + len(message.msg))
+
+	for _, arg := range message.args {
+		size += estimateValueSize(arg)
+	}
+
+	if message.attrs != nil {
+		for key, value := range message.attrs.Attrs() {
+			size += int64(len(key)) + estimateValueSize(value)
+		}
+	}
+
+	return size
+}
+
+func estimateValueSize(value interface{}) int64 {
+	switch v := value.(type) {
+	case nil:
+		return 0
+
+	case string:
+		return int64(len(v))
+
+	case []byte:
+		return int64(len(v))
+
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, time.Duration:
+		return 8
+
+	case time.Time:
+		return 24
+	}
+
+	// Let fmt call Error or String so that a nil receiver (or a panicking
+	// method) does not cause a panic
+	return int64(len(fmt.Sprint(value)))
+}
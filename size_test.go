@@ -0,0 +1,39 @@
+package gomolreplay
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aphistic/gomol"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *ReplaySuite) TestEstimateSize(c *C) {
+	message := &logMessage{
+		msg:   "foo %s %d",
+		args:  []interface{}{strings.Repeat("x", 1000), 42},
+		attrs: gomol.NewAttrsFromMap(map[string]interface{}{"key": []byte("value")}),
+	}
+
+	c.Assert(estimateSize(message), Equals, int64(messageOverhead+9+1000+8+3+5))
+}
+
+func (s *ReplaySuite) TestEstimateValueSize(c *C) {
+	c.Assert(estimateValueSize(nil), Equals, int64(0))
+	c.Assert(estimateValueSize("foo"), Equals, int64(3))
+	c.Assert(estimateValueSize(3.5), Equals, int64(8))
+	c.Assert(estimateValueSize(time.Second), Equals, int64(8))
+	c.Assert(estimateValueSize(errors.New("oops")), Equals, int64(4))
+	c.Assert(estimateValueSize(gomol.LevelDebug), Equals, int64(5))
+	c.Assert(estimateValueSize([]int{1, 2, 3}), Equals, int64(7))
+}
+
+func (s *ReplaySuite) TestEstimateValueSizeNilReceiver(c *C) {
+	c.Assert(estimateValueSize((*url.URL)(nil)), Equals, int64(len("<nil>")))
+
+	var err *url.Error
+	c.Assert(estimateValueSize(err), Equals, int64(len("<nil>")))
+}
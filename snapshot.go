@@ -0,0 +1,68 @@
+package gomolreplay
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aphistic/gomol"
+)
+
+// snapshot formats the message eagerly so that later mutation of its
+// arguments or attributes by the caller is not observed when the message
+// is replayed. The message's format string is replaced by "%s" and its
+// arguments by the formatted text. The attributes are copied, and any
+// attribute value which is not a scalar is replaced by its formatted text.
+func (m *logMessage) snapshot() {
+	if m.original == "" {
+		m.original = m.msg
+	}
+
+	m.args = []interface{}{formatMessage(m.msg, m.args)}
+	m.msg = "%s"
+
+	if m.attrs == nil {
+		return
+	}
+
+	attrs := gomol.NewAttrs()
+	for key, value := range m.attrs.Attrs() {
+		if !isScalar(value) {
+			value = fmt.Sprintf("%v", value)
+		}
+
+		attrs.SetAttr(key, value)
+	}
+
+	m.attrs = attrs
+}
+
+// formatMessage returns the text of a message logged with the given format
+// string and arguments. A message logged without arguments is not a format
+// string, so it is returned as-is.
+func formatMessage(msg string, args []interface{}) string {
+	if len(args) == 0 {
+		return msg
+	}
+
+	return fmt.Sprintf(msg, args...)
+}
+
+// template returns the format string with which the message was logged.
+func (m *logMessage) template() string {
+	if m.original != "" {
+		return m.original
+	}
+
+	return m.msg
+}
+
+// isScalar determines if the value is immutable (or copied by value) so
+// that it does not need to be formatted to be snapshotted.
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, time.Duration, time.Time, gomol.LogLevel:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,106 @@
+package gomolreplay
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aphistic/gomol"
+
+	. "gopkg.in/check.v1"
+)
+
+type snapshotTestValue struct {
+	count int
+}
+
+func (s *ReplaySuite) TestSnapshot(c *C) {
+	var (
+		value   = &snapshotTestValue{count: 1}
+		attrs   = gomol.NewAttrsFromMap(map[string]interface{}{"value": value, "x": 42})
+		message = &logMessage{msg: "count=%d value=%v", args: []interface{}{value.count, value}, attrs: attrs}
+	)
+
+	message.snapshot()
+	value.count = 2
+	attrs.SetAttr("y", 43)
+
+	c.Assert(message.msg, Equals, "%s")
+	c.Assert(message.args, DeepEquals, []interface{}{"count=1 value=&{1}"})
+	c.Assert(message.template(), Equals, "count=%d value=%v")
+	c.Assert(message.attrs.GetAttr("value"), Equals, "&{1}")
+	c.Assert(message.attrs.GetAttr("x"), Equals, 42)
+	c.Assert(message.attrs.GetAttr("y"), IsNil)
+}
+
+func (s *ReplaySuite) TestSnapshotLiteral(c *C) {
+	message := &logMessage{msg: "disk 100% full"}
+	message.snapshot()
+
+	c.Assert(message.msg, Equals, "%s")
+	c.Assert(message.args, DeepEquals, []interface{}{"disk 100% full"})
+	c.Assert(message.template(), Equals, "disk 100% full")
+}
+
+func (s *ReplaySuite) TestEagerFormatting(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		adapter  = NewConfiguredAdapter(logger, WithJournaledLevels(gomol.LevelDebug), WithEagerFormatting())
+		value    = &snapshotTestValue{count: 1}
+		messages = []logArgs{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		messages = append(messages, logArgs{level, attrs, msg, a})
+		return nil
+	}
+
+	adapter.Debugf("value=%v", value)
+	value.count = 2
+	adapter.Replay(gomol.LevelWarning)
+
+	c.Assert(len(messages), Equals, 2)
+	c.Assert(messages[1].msg, Equals, "%s")
+	c.Assert(messages[1].a, DeepEquals, []interface{}{"value=&{1}"})
+}
+
+func (s *ReplaySuite) TestTruncateSnapshot(c *C) {
+	message := &logMessage{msg: "value=%s", args: []interface{}{strings.Repeat("x", 30)}}
+	message.snapshot()
+
+	c.Assert(truncate(message, messageOverhead+2+20), Equals, true)
+	c.Assert(message.template(), Equals, "value=%s")
+	c.Assert(message.args, DeepEquals, []interface{}{"value" + truncatedSuffix})
+}
+
+func (s *ReplaySuite) TestTruncateLiteral(c *C) {
+	message := &logMessage{msg: "disk 100% full " + strings.Repeat("x", 30)}
+
+	c.Assert(truncate(message, messageOverhead+2+30), Equals, true)
+	c.Assert(message.msg, Equals, "%s")
+	c.Assert(message.args, DeepEquals, []interface{}{"disk 100% full " + truncatedSuffix})
+}
+
+//
+// Benchmarks
+
+func BenchmarkLazyFormatting(b *testing.B) {
+	benchmarkFormatting(b)
+}
+
+func BenchmarkEagerFormatting(b *testing.B) {
+	benchmarkFormatting(b, WithEagerFormatting())
+}
+
+func benchmarkFormatting(b *testing.B, configs ...ConfigFunc) {
+	var (
+		attrs   = gomol.NewAttrsFromMap(map[string]interface{}{"request": &snapshotTestValue{}, "id": 42})
+		adapter = NewConfiguredAdapter(newDefaultMockLogger(), append(configs, WithJournaledLevels(gomol.LevelDebug), WithMaxEntries(100))...)
+	)
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		adapter.Debugm(attrs, "handled request %d for user %s", i, "alice")
+	}
+}
@@ -0,0 +1,74 @@
+package gomolreplay
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aphistic/gomol"
+)
+
+// Watchdog replays the journal of an adapter if it is not stopped
+// before some condition occurs.
+type Watchdog struct {
+	stop  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+	fired bool
+}
+
+// StartWatchdog starts a timer which replays the journal at the given level
+// once the given duration has elapsed, unless the returned watchdog is stopped
+// first. Each replayed message is assigned the AttrElapsed attribute. Errors
+// from the wrapped logger during the replay are discarded.
+func (a *Adapter) StartWatchdog(deadline time.Duration, level gomol.LogLevel) *Watchdog {
+	var (
+		timer = a.clock.NewTimer(deadline)
+		start = a.clock.Now()
+	)
+
+	wait := func(stop <-chan struct{}) bool {
+		select {
+		case <-timer.Chan():
+			return true
+
+		case <-stop:
+			timer.Stop()
+			return false
+		}
+	}
+
+	return startWatchdog(wait, func() {
+		replaying := a.newReplay(level, "watchdog deadline exceeded")
+		replaying.attrs = gomol.NewAttrs().SetAttr(AttrElapsed, a.clock.Now().Sub(start))
+		a.replay(replaying)
+	})
+}
+
+// startWatchdog creates a watchdog which calls fire once wait returns true.
+// The wait function must return false once the stop channel is closed.
+func startWatchdog(wait func(stop <-chan struct{}) bool, fire func()) *Watchdog {
+	w := &Watchdog{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	go func() {
+		defer close(w.done)
+
+		if wait(w.stop) {
+			w.fired = true
+			fire()
+		}
+	}()
+
+	return w
+}
+
+// Stop stops the watchdog. If the watchdog is currently replaying the
+// journal, Stop blocks until the replay completes. Returns true if the
+// journal was not replayed by this watchdog.
+func (w *Watchdog) Stop() bool {
+	w.once.Do(func() { close(w.stop) })
+	<-w.done
+	return !w.fired
+}
@@ -0,0 +1,76 @@
+package gomolreplay
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aphistic/gomol"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *ReplaySuite) TestWatchdogReplays(c *C) {
+	var (
+		logger   = newDefaultMockLogger()
+		clock    = newMockClock(0)
+		adapter  = newAdapterWithClock(logger, clock, gomol.LevelDebug)
+		mutex    = sync.Mutex{}
+		messages = []logArgs{}
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if level == gomol.LevelWarning {
+			messages = append(messages, logArgs{level, attrs, msg, a})
+		}
+
+		return nil
+	}
+
+	watchdog := adapter.StartWatchdog(5*time.Second, gomol.LevelWarning)
+	adapter.Debug("foo")
+	clock.advance(3000)
+	adapter.Debug("bar")
+	clock.advance(3000)
+	<-watchdog.done
+
+	c.Assert(watchdog.Stop(), Equals, false)
+	c.Assert(len(messages), Equals, 2)
+	c.Assert(messages[0].msg, Equals, "foo")
+	c.Assert(messages[1].msg, Equals, "bar")
+	c.Assert(messages[0].attrs.GetAttr(AttrElapsed), Equals, 6*time.Second)
+	c.Assert(messages[1].attrs.GetAttr(AttrElapsed), Equals, 6*time.Second)
+
+	// Messages replayed immediately also carry the elapsed time
+	adapter.Debug("baz")
+	c.Assert(len(messages), Equals, 3)
+	c.Assert(messages[2].msg, Equals, "baz")
+	c.Assert(messages[2].attrs.GetAttr(AttrElapsed), Equals, 6*time.Second)
+}
+
+func (s *ReplaySuite) TestWatchdogStop(c *C) {
+	var (
+		logger  = newDefaultMockLogger()
+		clock   = newMockClock(0)
+		adapter = newAdapterWithClock(logger, clock, gomol.LevelDebug)
+		calls   = 0
+	)
+
+	logger.logWithTime = func(level gomol.LogLevel, ts time.Time, attrs *gomol.Attrs, msg string, a ...interface{}) error {
+		calls++
+		return nil
+	}
+
+	watchdog := adapter.StartWatchdog(5*time.Second, gomol.LevelWarning)
+	adapter.Debug("foo")
+	clock.advance(3000)
+
+	c.Assert(watchdog.Stop(), Equals, true)
+	c.Assert(watchdog.Stop(), Equals, true)
+	c.Assert(len(clock.timers), Equals, 0)
+
+	clock.advance(3000)
+	c.Assert(calls, Equals, 1)
+}